@@ -0,0 +1,619 @@
+package goorm
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// Association manages the related models of a relation of a model
+type Association struct {
+	q        *QueryBuilder
+	model    reflect.Value
+	schema   *schema
+	relation *relation
+	err      error
+}
+
+// Association returns the relation name of model for appending, removing
+// and replacing its related models, both in the database and in the model
+// e.g. qb.Association(&user, "Roles").Append(ctx, &admin)
+func (q *QueryBuilder) Association(model any, name string) *Association {
+	a := &Association{q: q}
+
+	v := reflect.ValueOf(model)
+	if v.Kind() != reflect.Pointer || v.Elem().Kind() != reflect.Struct {
+		a.err = fmt.Errorf("association: model must be a pointer to a struct")
+		return a
+	}
+
+	a.model = v.Elem()
+	a.schema = schemaOf(v.Type())
+	a.relation, a.err = a.schema.relation(name)
+	if a.err != nil {
+		a.err = fmt.Errorf("association: %w", a.err)
+	}
+	return a
+}
+
+// Append links the related models to the model
+func (a *Association) Append(ctx context.Context, values ...any) error {
+	related, err := a.related(values)
+	if err != nil {
+		return err
+	}
+	return a.append(ctx, a.q, related)
+}
+
+// Remove unlinks the related models from the model, the related models of
+// has one and has many relations get a NULL foreign key
+func (a *Association) Remove(ctx context.Context, values ...any) error {
+	related, err := a.related(values)
+	if err != nil || len(related) == 0 {
+		return err
+	}
+
+	rel := a.relation
+	if rel.Kind == belongsTo {
+		return a.unlinkBelongsTo(ctx, a.q)
+	}
+
+	keys, err := a.keysOf(related, rel.Schema, relatedKeyColumn(rel))
+	if err != nil {
+		return err
+	}
+
+	switch rel.Kind {
+	case hasOne, hasMany:
+		parentKey, err := a.keyOf(a.model, a.schema, rel.References)
+		if err != nil {
+			return err
+		}
+		update := a.q.fork().
+			Update(rel.Table).
+			Set(rel.ForeignKey, nil).
+			Where(rel.ForeignKey+" = ?", parentKey).
+			Where(inCondition(primaryKeyColumn(rel.Schema), len(keys)), keys...)
+		if err := execStatement(ctx, update); err != nil {
+			return err
+		}
+		for _, r := range related {
+			setColumn(r, rel.Schema, rel.ForeignKey, nil)
+		}
+	case manyToMany:
+		parentKey, err := a.keyOf(a.model, a.schema, rel.ForeignKey)
+		if err != nil {
+			return err
+		}
+		remove := a.q.fork().
+			Delete(rel.JoinTable).
+			Where(rel.JoinForeignKey+" = ?", parentKey).
+			Where(inCondition(rel.JoinReferences, len(keys)), keys...)
+		if err := execStatement(ctx, remove); err != nil {
+			return err
+		}
+	}
+
+	a.removeLoaded(keys)
+	return nil
+}
+
+// Replace links the model to exactly the related models, previous links are
+// removed in the same transaction
+func (a *Association) Replace(ctx context.Context, values ...any) error {
+	related, err := a.related(values)
+	if err != nil {
+		return err
+	}
+
+	return a.q.Transaction(ctx, func(tx *QueryBuilder) error {
+		if err := a.clear(ctx, tx); err != nil {
+			return err
+		}
+		return a.append(ctx, tx, related)
+	})
+}
+
+// append links the related models with the statements of q, which can be
+// the builder of a transaction
+func (a *Association) append(ctx context.Context, q *QueryBuilder, related []reflect.Value) error {
+	rel := a.relation
+	if len(related) == 0 {
+		return nil
+	}
+
+	switch rel.Kind {
+	case hasOne, hasMany:
+		if rel.Kind == hasOne && len(related) > 1 {
+			return fmt.Errorf("association: %s is a has one relation", rel.Name)
+		}
+		parentKey, err := a.keyOf(a.model, a.schema, rel.References)
+		if err != nil {
+			return err
+		}
+		keys, err := a.keysOf(related, rel.Schema, primaryKeyColumn(rel.Schema))
+		if err != nil {
+			return err
+		}
+		update := q.fork().
+			Update(rel.Table).
+			Set(rel.ForeignKey, parentKey).
+			Where(inCondition(primaryKeyColumn(rel.Schema), len(keys)), keys...)
+		if err := execStatement(ctx, update); err != nil {
+			return err
+		}
+		for _, r := range related {
+			setColumn(r, rel.Schema, rel.ForeignKey, parentKey)
+		}
+	case belongsTo:
+		if len(related) > 1 {
+			return fmt.Errorf("association: %s is a belongs to relation", rel.Name)
+		}
+		key, err := a.keyOf(related[0], rel.Schema, rel.References)
+		if err != nil {
+			return err
+		}
+		if err := a.setForeignKey(ctx, q, key); err != nil {
+			return err
+		}
+	case manyToMany:
+		if err := a.link(ctx, q, related, false); err != nil {
+			return err
+		}
+	}
+
+	a.appendLoaded(related)
+	return nil
+}
+
+// setForeignKey sets the foreign key of a belongs to relation of the model
+func (a *Association) setForeignKey(ctx context.Context, q *QueryBuilder, key any) error {
+	rel := a.relation
+	modelKey, err := a.keyOf(a.model, a.schema, primaryKeyColumn(a.schema))
+	if err != nil {
+		return err
+	}
+	update := q.fork().
+		Update(a.schema.Table).
+		Set(rel.ForeignKey, key).
+		Where(primaryKeyColumn(a.schema)+" = ?", modelKey)
+	if err := execStatement(ctx, update); err != nil {
+		return err
+	}
+	setColumn(a.model, a.schema, rel.ForeignKey, key)
+	return nil
+}
+
+// link inserts the rows of the join table of a many to many relation,
+// existing rows are kept as they are when ignoreExisting is set
+func (a *Association) link(ctx context.Context, q *QueryBuilder, related []reflect.Value, ignoreExisting bool) error {
+	rel := a.relation
+	parentKey, err := a.keyOf(a.model, a.schema, rel.ForeignKey)
+	if err != nil {
+		return err
+	}
+	keys, err := a.keysOf(related, rel.Schema, rel.References)
+	if err != nil {
+		return err
+	}
+
+	rows := make([][]any, len(keys))
+	for i, key := range keys {
+		rows[i] = []any{parentKey, key}
+	}
+	insert := q.fork().
+		InsertInto(rel.JoinTable).
+		Columns(rel.JoinForeignKey, rel.JoinReferences).
+		Values(rows)
+	if ignoreExisting && supportsUpsert(q.Dialect) {
+		insert.OnConflict().DoNothing()
+	}
+	return execStatement(ctx, insert)
+}
+
+// clear removes every link of the model
+func (a *Association) clear(ctx context.Context, q *QueryBuilder) error {
+	rel := a.relation
+	switch rel.Kind {
+	case hasOne, hasMany:
+		parentKey, err := a.keyOf(a.model, a.schema, rel.References)
+		if err != nil {
+			return err
+		}
+		update := q.fork().
+			Update(rel.Table).
+			Set(rel.ForeignKey, nil).
+			Where(rel.ForeignKey+" = ?", parentKey)
+		if err := execStatement(ctx, update); err != nil {
+			return err
+		}
+	case belongsTo:
+		return a.unlinkBelongsTo(ctx, q)
+	case manyToMany:
+		parentKey, err := a.keyOf(a.model, a.schema, rel.ForeignKey)
+		if err != nil {
+			return err
+		}
+		remove := q.fork().
+			Delete(rel.JoinTable).
+			Where(rel.JoinForeignKey+" = ?", parentKey)
+		if err := execStatement(ctx, remove); err != nil {
+			return err
+		}
+	}
+
+	field := a.model.Field(rel.Field)
+	field.Set(reflect.Zero(field.Type()))
+	return nil
+}
+
+func (a *Association) unlinkBelongsTo(ctx context.Context, q *QueryBuilder) error {
+	rel := a.relation
+	modelKey, err := a.keyOf(a.model, a.schema, primaryKeyColumn(a.schema))
+	if err != nil {
+		return err
+	}
+	update := q.fork().
+		Update(a.schema.Table).
+		Set(rel.ForeignKey, nil).
+		Where(primaryKeyColumn(a.schema)+" = ?", modelKey)
+	if err := execStatement(ctx, update); err != nil {
+		return err
+	}
+
+	setColumn(a.model, a.schema, rel.ForeignKey, nil)
+	field := a.model.Field(rel.Field)
+	field.Set(reflect.Zero(field.Type()))
+	return nil
+}
+
+// appendLoaded adds the related models to the relation field of the model
+func (a *Association) appendLoaded(related []reflect.Value) {
+	field := a.model.Field(a.relation.Field)
+	if field.Kind() != reflect.Slice {
+		setRelated(field, related)
+		return
+	}
+
+	isPtr := field.Type().Elem().Kind() == reflect.Pointer
+	for _, r := range related {
+		if isPtr {
+			if !r.CanAddr() {
+				continue
+			}
+			field.Set(reflect.Append(field, r.Addr()))
+		} else {
+			field.Set(reflect.Append(field, r))
+		}
+	}
+}
+
+// removeLoaded drops the related models with the given keys from the
+// relation field of the model
+func (a *Association) removeLoaded(keys []any) {
+	removed := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		removed[fmt.Sprint(key)] = true
+	}
+
+	column := relatedKeyColumn(a.relation)
+	f, ok := a.relation.Schema.columns[column]
+	if !ok {
+		return
+	}
+
+	field := a.model.Field(a.relation.Field)
+	switch field.Kind() {
+	case reflect.Slice:
+		kept := reflect.MakeSlice(field.Type(), 0, field.Len())
+		for i := 0; i < field.Len(); i++ {
+			elem := field.Index(i)
+			if elem.Kind() == reflect.Pointer && elem.IsNil() {
+				continue
+			}
+			if key, ok := valueKey(reflect.Indirect(elem).Field(f.Index)); ok && removed[key] {
+				continue
+			}
+			kept = reflect.Append(kept, elem)
+		}
+		field.Set(kept)
+	case reflect.Pointer, reflect.Struct:
+		elem := reflect.Indirect(field)
+		if !elem.IsValid() {
+			return
+		}
+		if key, ok := valueKey(elem.Field(f.Index)); ok && removed[key] {
+			field.Set(reflect.Zero(field.Type()))
+		}
+	}
+}
+
+// related returns the structs of the given related models
+func (a *Association) related(values []any) ([]reflect.Value, error) {
+	if a.err != nil {
+		return nil, a.err
+	}
+
+	related := make([]reflect.Value, 0, len(values))
+	for _, value := range values {
+		v := reflect.ValueOf(value)
+		for v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				return nil, fmt.Errorf("association: nil %s", a.relation.Name)
+			}
+			v = v.Elem()
+		}
+		if v.Type() != a.relation.Schema.Type {
+			return nil, fmt.Errorf("association: %s expects %s, got %s", a.relation.Name, a.relation.Schema.Type.Name(), v.Type())
+		}
+		related = append(related, v)
+	}
+	return related, nil
+}
+
+// keyOf returns the value of the column of a model, which must be set
+func (a *Association) keyOf(v reflect.Value, s *schema, column string) (any, error) {
+	f, ok := s.columns[column]
+	if !ok {
+		return nil, fmt.Errorf("association: %s has no field for column %s", s.Type.Name(), column)
+	}
+	value := v.Field(f.Index)
+	if _, ok := valueKey(value); !ok || value.IsZero() {
+		return nil, fmt.Errorf("association: %s.%s is not set", s.Type.Name(), f.Name)
+	}
+	return reflect.Indirect(value).Interface(), nil
+}
+
+func (a *Association) keysOf(values []reflect.Value, s *schema, column string) ([]any, error) {
+	keys := make([]any, len(values))
+	for i, v := range values {
+		key, err := a.keyOf(v, s, column)
+		if err != nil {
+			return nil, err
+		}
+		keys[i] = key
+	}
+	return keys, nil
+}
+
+// execStatement executes a statement without rows
+func execStatement(ctx context.Context, q *QueryBuilder) error {
+	rows, err := q.Exec(ctx)
+	if err != nil {
+		return err
+	}
+	return rows.Close()
+}
+
+// inCondition returns a condition on column with n ? placeholders
+// e.g. id IN (?, ?)
+func inCondition(column string, n int) string {
+	return column + " IN (" + strings.TrimSuffix(strings.Repeat("?, ", n), ", ") + ")"
+}
+
+// relatedKeyColumn returns the column identifying a related model
+func relatedKeyColumn(rel *relation) string {
+	switch rel.Kind {
+	case belongsTo, manyToMany:
+		return rel.References
+	}
+	return primaryKeyColumn(rel.Schema)
+}
+
+// setColumn sets the field of column on an addressable model, value nil
+// sets the zero value
+func setColumn(v reflect.Value, s *schema, column string, value any) {
+	f, ok := s.columns[column]
+	if !ok || !v.CanAddr() {
+		return
+	}
+
+	field := v.Field(f.Index)
+	if value == nil {
+		field.Set(reflect.Zero(field.Type()))
+		return
+	}
+
+	rv := reflect.ValueOf(value)
+	target := field.Type()
+	if target.Kind() == reflect.Pointer {
+		target = target.Elem()
+	}
+	if !rv.Type().ConvertibleTo(target) {
+		return
+	}
+	rv = rv.Convert(target)
+	if field.Kind() == reflect.Pointer {
+		ptr := reflect.New(target)
+		ptr.Elem().Set(rv)
+		rv = ptr
+	}
+	field.Set(rv)
+}
+
+// loadedRelations returns the relations of a model whose fields hold
+// related models. Struct fields without relation tags which do not resolve
+// to a relation are nested structs and left out.
+func loadedRelations(v reflect.Value, s *schema) ([]*relation, error) {
+	var relations []*relation
+	for i := 0; i < s.Type.NumField(); i++ {
+		sf := s.Type.Field(i)
+		if !sf.IsExported() || !isRelationField(sf) || !isLoaded(v.Field(i)) {
+			continue
+		}
+		rel, err := s.relation(sf.Name)
+		if err != nil {
+			if hasRelationTags(sf) {
+				return nil, err
+			}
+			continue
+		}
+		relations = append(relations, rel)
+	}
+	return relations, nil
+}
+
+// hasRelationTags reports whether a field declares its relation with tags
+func hasRelationTags(sf reflect.StructField) bool {
+	options := parseTagOptions(sf.Tag.Get("goorm"))
+	for _, key := range []string{"foreignkey", "references", "many2many"} {
+		if _, ok := options[key]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// isLoaded reports whether a relation field holds related models
+func isLoaded(field reflect.Value) bool {
+	switch field.Kind() {
+	case reflect.Slice:
+		return field.Len() > 0
+	case reflect.Pointer:
+		return !field.IsNil()
+	}
+	return !field.IsZero()
+}
+
+// hasLoadedRelations reports whether one of the models holds related
+// models which Save saves as well
+func hasLoadedRelations(models []reflect.Value) bool {
+	for _, model := range models {
+		if relations, err := loadedRelations(model, schemaOf(model.Type())); err != nil || len(relations) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// saveBelongsTo inserts the models the models to insert or update belong
+// to when they have no primary key yet and sets the foreign keys of the
+// models, in the rows to insert as well. The foreign keys the statement
+// does not write are returned to be set once the models are saved.
+func (q *QueryBuilder) saveBelongsTo(ctx context.Context, models []reflect.Value) ([]*Association, error) {
+	var pending []*Association
+	for i, model := range models {
+		s := schemaOf(model.Type())
+		relations, err := loadedRelations(model, s)
+		if err != nil {
+			return nil, err
+		}
+		for _, rel := range relations {
+			if rel.Kind != belongsTo {
+				continue
+			}
+			a := &Association{q: q, model: model, schema: s, relation: rel}
+			related := modelStructs(model.Field(rel.Field))
+			if len(related) == 0 {
+				continue
+			}
+			if err := q.saveRelated(ctx, rel, related[0], false); err != nil {
+				return nil, err
+			}
+			key, err := a.keyOf(related[0], rel.Schema, rel.References)
+			if err != nil {
+				return nil, err
+			}
+			setColumn(model, s, rel.ForeignKey, key)
+
+			if !q.setInsertedValue(i, rel.ForeignKey, key) {
+				pending = append(pending, a)
+			}
+		}
+	}
+	return pending, nil
+}
+
+// setInsertedValue sets the value of a column in the row to insert of the
+// i-th model, it reports false when the insert does not have the column
+func (q *QueryBuilder) setInsertedValue(i int, column string, value any) bool {
+	if i >= len(q.rows) {
+		return false
+	}
+	for j, c := range q.columns {
+		if c == column && j < len(q.rows[i]) {
+			q.rows[i][j] = value
+			q.params[q.valuesOffset+i*len(q.rows[i])+j] = value
+			return true
+		}
+	}
+	return false
+}
+
+// saveAssociations saves the related models held by the relation fields of
+// the saved models and links them to the models. Related models without a
+// primary key are inserted, the others are updated, or only linked for
+// many to many relations.
+func (q *QueryBuilder) saveAssociations(ctx context.Context, models []reflect.Value, pending []*Association) error {
+	for _, a := range pending {
+		key, err := a.keyOf(a.model, a.schema, a.relation.ForeignKey)
+		if err != nil {
+			return err
+		}
+		if err := a.setForeignKey(ctx, q, key); err != nil {
+			return err
+		}
+	}
+
+	for _, model := range models {
+		s := schemaOf(model.Type())
+		relations, err := loadedRelations(model, s)
+		if err != nil {
+			return err
+		}
+		for _, rel := range relations {
+			a := &Association{q: q, model: model, schema: s, relation: rel}
+			related := modelStructs(model.Field(rel.Field))
+			switch rel.Kind {
+			case hasOne, hasMany:
+				parentKey, err := a.keyOf(model, s, rel.References)
+				if err != nil {
+					return err
+				}
+				for _, r := range related {
+					setColumn(r, rel.Schema, rel.ForeignKey, parentKey)
+					if err := q.saveRelated(ctx, rel, r, true); err != nil {
+						return err
+					}
+				}
+			case manyToMany:
+				for _, r := range related {
+					if err := q.saveRelated(ctx, rel, r, false); err != nil {
+						return err
+					}
+				}
+				if err := a.link(ctx, q, related, true); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// saveRelated inserts a related model without a primary key, or updates
+// it when update is set, along with its own related models
+func (q *QueryBuilder) saveRelated(ctx context.Context, rel *relation, r reflect.Value, update bool) error {
+	if !r.CanAddr() {
+		return fmt.Errorf("association: %s must be addressable to be saved", rel.Name)
+	}
+	model := r.Addr().Interface()
+	if _, ok := rel.Schema.primaryKeyOf(r); !ok {
+		return q.fork().InsertInto(rel.Table).Model(model).Save(ctx)
+	}
+	if !update {
+		return nil
+	}
+	pk := rel.Schema.PrimaryKey
+	return q.fork().
+		Update(rel.Table).
+		SetModel(model).
+		Where(pk.Column+" = ?", r.Field(pk.Index).Interface()).
+		Save(ctx)
+}
@@ -0,0 +1,183 @@
+package goorm
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+)
+
+// BulkInsert inserts rows into the columns of table as fast as the database
+// allows. Rows are []any values in the order of columns or models, whose
+// columns default to the ones mapped by their db tags but the ones the
+// database generates, the primary key and fields tagged auto_increment or
+// default. Name the columns to insert these.
+// On PostgreSQL the rows are streamed with COPY, other databases and
+// transactions use multi-row INSERTs split by the parameter limit, which run
+// in a transaction so that no row is inserted when one of them fails.
+// e.g.
+//
+//	n, err := goorm.BulkInsert(ctx, qb, "users", []string{"name", "email"}, users)
+func BulkInsert[T any](ctx context.Context, q *QueryBuilder, table string, columns []string, rows []T) (int64, error) {
+	return BulkInsertSeq(ctx, q, table, columns, slices.Values(rows))
+}
+
+// BulkInsertSeq works like BulkInsert but reads the rows from an iterator,
+// so they do not need to be held in memory
+func BulkInsertSeq[T any](ctx context.Context, q *QueryBuilder, table string, columns []string, rows iter.Seq[T]) (int64, error) {
+	toValues, columns, err := rowValues[T](columns)
+	if err != nil {
+		return 0, err
+	}
+
+	next, stop := iter.Pull(rows)
+	defer stop()
+
+	read := func() ([]any, error) {
+		row, ok := next()
+		if !ok {
+			return nil, nil
+		}
+		values := toValues(row)
+		if len(values) != len(columns) {
+			return nil, fmt.Errorf("bulk insert: row has %d values for %d columns", len(values), len(columns))
+		}
+		return values, nil
+	}
+
+	if q.Dialect.GetName() == Postgres && q.tx == nil {
+		n, err, ok := q.copyFrom(ctx, table, columns, read)
+		if ok {
+			return n, err
+		}
+	}
+	return q.insertBatches(ctx, table, columns, read)
+}
+
+// rowValues returns the function turning a row into the values of columns,
+// which default to the columns of a model
+func rowValues[T any](columns []string) (func(T) []any, []string, error) {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	if t == reflect.TypeOf([]any(nil)) {
+		if len(columns) == 0 {
+			return nil, nil, fmt.Errorf("bulk insert: columns are required for rows of values")
+		}
+		return func(row T) []any { return any(row).([]any) }, columns, nil
+	}
+
+	elem := t
+	if elem.Kind() == reflect.Pointer {
+		elem = elem.Elem()
+	}
+	if elem.Kind() != reflect.Struct {
+		return nil, nil, fmt.Errorf("bulk insert: unsupported row type %s", t)
+	}
+
+	s := schemaOf(elem)
+	var fields []*field
+	if len(columns) == 0 {
+		for _, f := range s.columnFields() {
+			if !s.isGenerated(f) {
+				fields = append(fields, f)
+				columns = append(columns, f.Column)
+			}
+		}
+	} else {
+		for _, column := range columns {
+			f := s.lookup(column)
+			if f == nil {
+				return nil, nil, fmt.Errorf("bulk insert: %s has no field for column %s", elem.Name(), column)
+			}
+			fields = append(fields, f)
+		}
+	}
+
+	return func(row T) []any {
+		v := reflect.Indirect(reflect.ValueOf(row))
+		values := make([]any, len(fields))
+		for i, f := range fields {
+			values[i] = v.Field(f.Index).Interface()
+		}
+		return values
+	}, columns, nil
+}
+
+// copyFrom streams the rows with COPY, ok is false when the connection is
+// not a pgx connection
+func (q *QueryBuilder) copyFrom(ctx context.Context, table string, columns []string, read func() ([]any, error)) (n int64, err error, ok bool) {
+	conn, err := q.db.Conn(ctx)
+	if err != nil {
+		return 0, err, true
+	}
+	defer conn.Close()
+
+	q.logger.Info(fmt.Sprintf("COPY %s (%s) FROM STDIN", table, strings.Join(columns, ", ")))
+	err = conn.Raw(func(driverConn any) error {
+		c, isPgx := driverConn.(*stdlib.Conn)
+		if !isPgx {
+			return nil
+		}
+		ok = true
+		n, err = c.Conn().CopyFrom(ctx, pgx.Identifier(strings.Split(table, ".")), columns, pgx.CopyFromFunc(read))
+		return err
+	})
+	if err != nil {
+		q.logger.Error(err.Error())
+	}
+	return n, err, ok
+}
+
+// insertBatches inserts the rows with one multi-row INSERT per batch of as
+// many rows as the parameter limit of the dialect allows. The batches run in
+// a transaction, the one of the builder when it has one.
+func (q *QueryBuilder) insertBatches(ctx context.Context, table string, columns []string, read func() ([]any, error)) (int64, error) {
+	size := max(maxParams(q.Dialect)/max(len(columns), 1), 1)
+	names := make([]any, len(columns))
+	for i, column := range columns {
+		names[i] = column
+	}
+
+	var total int64
+	err := q.Transaction(ctx, func(tx *QueryBuilder) error {
+		batch := make([][]any, 0, size)
+		flush := func() error {
+			if len(batch) == 0 {
+				return nil
+			}
+			rows, err := tx.fork().InsertInto(table).Columns(names...).Values(batch).Exec(ctx)
+			if err != nil {
+				return err
+			}
+			total += int64(len(batch))
+			batch = batch[:0]
+			return rows.Close()
+		}
+
+		for {
+			values, err := read()
+			if err != nil {
+				return err
+			}
+			if values == nil {
+				break
+			}
+			batch = append(batch, values)
+			if len(batch) == size {
+				if err := flush(); err != nil {
+					return err
+				}
+			}
+		}
+		return flush()
+	})
+	if err != nil && q.tx == nil {
+		// the inserted batches were rolled back
+		return 0, err
+	}
+	return total, err
+}
@@ -0,0 +1,90 @@
+package goorm
+
+import "strings"
+
+// CaseExpr is a CASE expression whose values are bound parameters e.g.
+//
+//	goorm.Case().When("age < 18", "minor").Else("adult").End().As("label")
+//
+// or, comparing a column with each WHEN value,
+//
+//	goorm.Case("status").When("a", "active").When("b", "blocked").End()
+type CaseExpr struct {
+	value    string
+	whens    []caseWhen
+	elseExpr any
+	hasElse  bool
+}
+
+type caseWhen struct {
+	condition any
+	result    any
+}
+
+// Case starts a searched CASE, whose WHEN conditions are raw SQL or
+// expressions, or a simple CASE of the column, whose WHEN values are bound
+func Case(column ...string) *CaseExpr {
+	c := &CaseExpr{}
+	if len(column) > 0 {
+		c.value = column[0]
+	}
+	return c
+}
+
+// When adds a WHEN branch, result is bound unless it is an expression or a
+// *QueryBuilder
+func (c *CaseExpr) When(condition any, result any) *CaseExpr {
+	c.whens = append(c.whens, caseWhen{condition: condition, result: result})
+	return c
+}
+
+// Else sets the result when no branch matches, NULL otherwise
+func (c *CaseExpr) Else(result any) *CaseExpr {
+	c.elseExpr = result
+	c.hasElse = true
+	return c
+}
+
+// End ends the CASE expression
+func (c *CaseExpr) End() *CaseExpr {
+	return c
+}
+
+// As names the result of the CASE in a SELECT
+func (c *CaseExpr) As(name string) Expression {
+	return As(c, name)
+}
+
+func (c *CaseExpr) ToSQL(dialect Dialect) (string, []any) {
+	var args []any
+	operand := func(value any, raw bool) string {
+		if s, ok := value.(string); ok && raw {
+			return s
+		}
+		if isQuery(value) {
+			query, params, _ := toSQL(dialect, value)
+			query = shiftPlaceholders(query, len(args))
+			args = append(args, params...)
+			return query
+		}
+		args = append(args, value)
+		return dialect.GetPlaceholder(len(args))
+	}
+
+	var b strings.Builder
+	b.WriteString("CASE")
+	if c.value != "" {
+		b.WriteString(" " + c.value)
+	}
+	for _, when := range c.whens {
+		// the conditions of a searched CASE are SQL, the values of a simple
+		// CASE are bound
+		b.WriteString(" WHEN " + operand(when.condition, c.value == ""))
+		b.WriteString(" THEN " + operand(when.result, false))
+	}
+	if c.hasElse {
+		b.WriteString(" ELSE " + operand(c.elseExpr, false))
+	}
+	b.WriteString(" END")
+	return b.String(), args
+}
@@ -0,0 +1,206 @@
+package goorm
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Chunk runs the query in batches of size models ordered by the primary key
+// of T and calls fn with every batch. Batches are paged by the last primary
+// key seen, e.g. WHERE id > $1 ORDER BY id LIMIT 100, rather than with an
+// offset so rows are neither skipped nor repeated while fn updates them.
+// Processing stops at the first error returned by fn.
+// e.g.
+//
+//	err := goorm.Chunk(ctx, qb.Select().From("users"), 100, func(users []User) error {
+//		...
+//	})
+//
+// The query must not have a GROUP BY, HAVING, WINDOW, ORDER BY, LIMIT,
+// OFFSET or locking clause nor set operations, which fail with an error,
+// and OR conditions must be enclosed in parentheses. LIMIT counts rows, not
+// models, so joins repeating the rows of a model, such as a has many
+// relation, split it across batches; load such relations with Preload.
+func Chunk[T any](ctx context.Context, q *QueryBuilder, size int, fn func(batch []T) error) error {
+	return chunk(ctx, q, size, func(_ *QueryBuilder, batch []T) error {
+		return fn(batch)
+	}, false)
+}
+
+// ChunkTx works like Chunk but runs every batch in its own transaction.
+// The queries of the QueryBuilder passed to fn are part of it, the
+// transaction is committed when fn returns nil and rolled back otherwise.
+// e.g.
+//
+//	err := goorm.ChunkTx(ctx, qb.Select().From("users"), 100, func(tx *goorm.QueryBuilder, users []User) error {
+//		return tx.Update("users").Set("active", true).Where(...).Save(ctx)
+//	})
+func ChunkTx[T any](ctx context.Context, q *QueryBuilder, size int, fn func(tx *QueryBuilder, batch []T) error) error {
+	return chunk(ctx, q, size, fn, true)
+}
+
+// ChunkByID works like Chunk but pages by column, which must be unique and
+// mapped to a field of T e.g. ChunkByID(ctx, q, "users.id", 100, fn)
+func ChunkByID[T any](ctx context.Context, q *QueryBuilder, column string, size int, fn func(batch []T) error) error {
+	return chunkByID(ctx, q, column, size, func(_ *QueryBuilder, batch []T) error {
+		return fn(batch)
+	}, false)
+}
+
+// ChunkByIDTx works like ChunkTx but pages by column like ChunkByID
+func ChunkByIDTx[T any](ctx context.Context, q *QueryBuilder, column string, size int, fn func(tx *QueryBuilder, batch []T) error) error {
+	return chunkByID(ctx, q, column, size, fn, true)
+}
+
+func chunk[T any](ctx context.Context, q *QueryBuilder, size int, fn func(tx *QueryBuilder, batch []T) error, transaction bool) error {
+	s := schemaOf(reflect.TypeOf((*T)(nil)).Elem())
+	if s.PrimaryKey == nil {
+		q.Reset()
+		return fmt.Errorf("chunk: %s has no primary key", s.Type.Name())
+	}
+
+	column := s.PrimaryKey.Column
+	if q.currentTable != "" {
+		column = q.currentTable + "." + column
+	}
+	return chunkByID(ctx, q, column, size, fn, transaction)
+}
+
+func chunkByID[T any](ctx context.Context, q *QueryBuilder, column string, size int, fn func(tx *QueryBuilder, batch []T) error, transaction bool) error {
+	snap, err := q.snapshot()
+	if err != nil {
+		return err
+	}
+	if err := snap.check("chunk", keysetClauses...); err != nil {
+		return err
+	}
+	if size <= 0 {
+		return fmt.Errorf("chunk: size must be greater than 0")
+	}
+
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return fmt.Errorf("chunk: %s is not a model", t)
+	}
+
+	name := column
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		name = name[i+1:]
+	}
+	key, ok := schemaOf(t).columns[name]
+	if !ok {
+		return fmt.Errorf("chunk: %s has no field for column %s", t.Name(), name)
+	}
+
+	var last any
+	fetch := func(sub *QueryBuilder) ([]T, error) {
+		snap.replay(sub)
+
+		if last != nil {
+			sub.query.WriteString(snap.clause() + sub.quoteName(column) + " > " + sub.Dialect.GetPlaceholder(len(sub.params)+1))
+			sub.params = append(sub.params, last)
+		}
+		sub.query.WriteString(" ORDER BY " + sub.quoteName(column) + " LIMIT " + strconv.Itoa(size))
+
+		var batch []T
+		if err := sub.Scan(ctx, &batch); err != nil {
+			return nil, err
+		}
+		return batch, nil
+	}
+
+	for {
+		var batch []T
+		if transaction {
+			err = q.Transaction(ctx, func(tx *QueryBuilder) error {
+				if batch, err = fetch(tx.fork()); err != nil || len(batch) == 0 {
+					return err
+				}
+				return fn(tx, batch)
+			})
+		} else if batch, err = fetch(q.fork()); err == nil && len(batch) > 0 {
+			err = fn(q, batch)
+		}
+		if err != nil {
+			return err
+		}
+
+		if len(batch) < size {
+			return nil
+		}
+		last = reflect.Indirect(reflect.ValueOf(batch[len(batch)-1])).Field(key.Index).Interface()
+	}
+}
+
+// snapshot is a query captured to be replayed by helpers running it several
+// times such as Chunk and Paginate
+type snapshot struct {
+	query    string
+	table    string
+	params   []interface{}
+	hasWhere bool
+	preloads []preload
+	mode     MappingMode
+	// clauses are the clauses of the query and locked whether it has a
+	// locking clause, the helpers append their own after the query
+	clauses []string
+	locked  bool
+}
+
+// keysetClauses are the clauses a query paged by key cannot have, as its
+// conditions and ORDER BY are appended to the query
+var keysetClauses = []string{"GROUP BY", "HAVING", "WINDOW", "UNION", "UNION ALL", "INTERSECT", "EXCEPT", "ORDER BY", "LIMIT", "OFFSET"}
+
+// snapshot captures the query and resets the builder
+func (q *QueryBuilder) snapshot() (*snapshot, error) {
+	query, params := q.build()
+	snap := &snapshot{
+		query:    strings.TrimSuffix(strings.TrimSpace(query), ";"),
+		table:    q.currentTable,
+		params:   params,
+		hasWhere: hasOperation(q.operations, "WHERE"),
+		preloads: q.preloads,
+		mode:     q.mappingMode(),
+		clauses:  append([]string(nil), q.operations...),
+		locked:   q.lock != nil,
+	}
+	err := q.err
+	q.Reset()
+	return snap, err
+}
+
+// replay writes the captured query into sub
+func (s *snapshot) replay(sub *QueryBuilder) {
+	sub.query.WriteString(s.query)
+	sub.params = append(sub.params, s.params...)
+	sub.preloads = s.preloads
+	sub.mapping = s.mode
+}
+
+// check reports the first of clauses the captured query has, or a locking
+// clause, which the helper cannot append its own clauses to
+func (s *snapshot) check(helper string, clauses ...string) error {
+	for _, clause := range clauses {
+		if hasOperation(s.clauses, clause) {
+			return fmt.Errorf("%s: cannot page a query with %s", helper, clause)
+		}
+	}
+	if s.locked {
+		return fmt.Errorf("%s: cannot page a query with a locking clause", helper)
+	}
+	return nil
+}
+
+// clause returns the keyword joining a condition to the captured query
+func (s *snapshot) clause() string {
+	if s.hasWhere {
+		return " AND "
+	}
+	return " WHERE "
+}
@@ -0,0 +1,85 @@
+package goorm
+
+import (
+	"fmt"
+	"strings"
+)
+
+// cte is a common table expression of a query
+type cte struct {
+	name      string
+	query     string
+	params    []any
+	recursive bool
+}
+
+// With adds a common table expression named name, which can list its
+// columns e.g. "totals(user_id, total)", to the query. The parameters of
+// sub are numbered before the ones of the query, which keeps numbering
+// its placeholders from 1.
+// e.g.
+//
+//	active := goorm.NewQueryBuilder(db, dialect, nil).Select("id").From("users").Where("active = $1", true)
+//	qb.With("active_users", active).Select().From("posts").Where("user_id IN (SELECT id FROM active_users)")
+func (q *QueryBuilder) With(name string, sub *QueryBuilder) *QueryBuilder {
+	return q.with(name, sub, false)
+}
+
+// WithRecursive adds a recursive common table expression, sub usually
+// combines the anchor and recursive queries with UnionAll
+func (q *QueryBuilder) WithRecursive(name string, sub *QueryBuilder) *QueryBuilder {
+	return q.with(name, sub, true)
+}
+
+func (q *QueryBuilder) with(name string, sub *QueryBuilder, recursive bool) *QueryBuilder {
+	if !supportsCTE(q.Dialect) {
+		q.err = fmt.Errorf("%s does not support common table expressions", dialectName(q.Dialect))
+		return q
+	}
+	if sub.err != nil {
+		q.err = sub.err
+		sub.Reset()
+		return q
+	}
+
+	// the parameters of sub include the ones of its set operations
+	query, params := sub.build()
+	sub.Reset()
+	q.ctes = append(q.ctes, cte{
+		name:      name,
+		query:     strings.TrimSuffix(strings.TrimSpace(query), ";"),
+		params:    params,
+		recursive: recursive,
+	})
+	return q
+}
+
+// withSQL renders the WITH clause and returns its parameters
+func (q *QueryBuilder) withSQL() (string, []any) {
+	var args []any
+	var recursive bool
+	parts := make([]string, len(q.ctes))
+	for i, c := range q.ctes {
+		parts[i] = fmt.Sprintf("%s AS (%s)", c.name, shiftPlaceholders(c.query, len(args)))
+		args = append(args, c.params...)
+		recursive = recursive || c.recursive
+	}
+
+	keyword := "WITH "
+	if recursive {
+		keyword = "WITH RECURSIVE "
+	}
+	return keyword + strings.Join(parts, ", ") + " ", args
+}
+
+// checkCTEs reports common table expressions the statement cannot have,
+// MySQL only accepts them inside the SELECT of an INSERT
+func (q *QueryBuilder) checkCTEs() error {
+	if len(q.ctes) == 0 || q.Dialect.GetName() != Mysql {
+		return nil
+	}
+	if strings.HasPrefix(strings.ToUpper(strings.TrimSpace(q.query.String())), "INSERT") {
+		return fmt.Errorf("%s does not support common table expressions before INSERT", dialectName(q.Dialect))
+	}
+	return nil
+}
@@ -0,0 +1,247 @@
+package goorm
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"iter"
+	"reflect"
+)
+
+// Cursor streams the result of a query one row at a time instead of
+// loading every row in memory like Scan. T is a model, a pointer to a
+// model or any value Scan accepts e.g. int64, map[string]any or a tuple.
+//
+// Joined rows of has many relations are merged into their model as long
+// as they follow each other, so the query should be ordered by the primary
+// key of the model. Preloads are not applied to cursors.
+type Cursor[T any] struct {
+	q        *QueryBuilder
+	rows     *sql.Rows
+	columns  []string
+	selected map[string]bool
+	values   []interface{}
+	pointers []interface{}
+	pending  bool
+	done     bool
+	current  T
+	err      error
+
+	// scan fills values that are not models
+	scan func(columns []string, values []interface{}, dest reflect.Value) error
+
+	elemType reflect.Type
+	isPtr    bool
+	schema   *schema
+	grouped  bool
+	claimed  map[string]bool
+	children map[string]int
+	tracker  *mappingTracker
+	mode     MappingMode
+}
+
+// NewCursor executes the query and returns a cursor over its rows, the
+// cursor must be closed once done with it
+// e.g.
+//
+//	cursor, err := goorm.NewCursor[User](ctx, qb.Select().From("users"))
+//	defer cursor.Close()
+//	for cursor.Next() {
+//		user := cursor.Value()
+//	}
+func NewCursor[T any](ctx context.Context, q *QueryBuilder) (*Cursor[T], error) {
+	mode := q.mappingMode()
+	rows, err := q.Exec(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	columns, err := rows.Columns()
+	if err != nil {
+		rows.Close()
+		return nil, err
+	}
+
+	c := &Cursor[T]{
+		q:        q,
+		rows:     rows,
+		columns:  columns,
+		selected: make(map[string]bool),
+		values:   make([]interface{}, len(columns)),
+		pointers: make([]interface{}, len(columns)),
+		mode:     mode,
+	}
+	for i, column := range columns {
+		c.selected[column] = true
+		c.pointers[i] = &c.values[i]
+	}
+
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	if c.scan = valueScanner(t); c.scan != nil {
+		c.elemType = t
+		return c, nil
+	}
+
+	c.elemType = t
+	if t.Kind() == reflect.Pointer {
+		c.elemType = t.Elem()
+		c.isPtr = true
+	}
+	if c.elemType.Kind() != reflect.Struct {
+		rows.Close()
+		return nil, fmt.Errorf("cursor: unsupported type %s", t)
+	}
+
+	c.schema = schemaOf(c.elemType)
+	c.grouped = len(c.schema.hasManyFields()) > 0
+	c.claimed = claimedColumns(nil, c.schema)
+	if mode == MappingLenient || mode == MappingStrict {
+		c.tracker = newMappingTracker()
+	}
+	return c, nil
+}
+
+// Next prepares the next value for Value, it returns false when there are
+// no more rows or an error occurred
+func (c *Cursor[T]) Next() bool {
+	if c.done {
+		return false
+	}
+
+	var dest reflect.Value
+	key, hasKey := "", false
+	for {
+		if !c.pending {
+			if !c.rows.Next() {
+				c.err = c.rows.Err()
+				c.Close()
+				break
+			}
+			if err := c.rows.Scan(c.pointers...); err != nil {
+				return c.fail(err)
+			}
+		}
+		c.pending = false
+
+		if c.scan != nil {
+			dest = reflect.New(c.elemType).Elem()
+			if err := c.scan(c.columns, c.values, dest); err != nil {
+				return c.fail(err)
+			}
+			break
+		}
+
+		valueMap := make(map[string]interface{}, len(c.columns))
+		for i, column := range c.columns {
+			valueMap[column] = c.values[i]
+		}
+
+		row := reflect.New(c.elemType).Elem()
+		if err := fillStruct(row, valueMap, c.selected, c.tracker); err != nil {
+			return c.fail(err)
+		}
+
+		if dest.IsValid() {
+			// the row either adds children to the current model or starts
+			// the next one, which is kept for the following call
+			if k, ok := c.schema.primaryKeyOf(row); ok && hasKey && k == key {
+				if err := appendHasMany(dest, key, valueMap, c.children, c.claimed, nil); err != nil {
+					return c.fail(err)
+				}
+				continue
+			}
+			c.pending = true
+			break
+		}
+
+		dest = row
+		if c.grouped {
+			key, hasKey = c.schema.primaryKeyOf(row)
+			c.children = make(map[string]int)
+			if err := appendHasMany(dest, key, valueMap, c.children, c.claimed, c.tracker); err != nil {
+				return c.fail(err)
+			}
+		}
+
+		if c.tracker != nil {
+			if err := c.q.reportMapping(c.elemType, c.columns, c.tracker, c.mode); err != nil {
+				return c.fail(err)
+			}
+			c.tracker = nil
+		}
+
+		if !c.grouped {
+			break
+		}
+	}
+
+	if !dest.IsValid() {
+		return false
+	}
+
+	if c.isPtr {
+		ptr := reflect.New(c.elemType)
+		ptr.Elem().Set(dest)
+		c.current = ptr.Interface().(T)
+	} else {
+		c.current = dest.Interface().(T)
+	}
+	return true
+}
+
+// Value returns the value prepared by Next
+func (c *Cursor[T]) Value() T {
+	return c.current
+}
+
+// Err returns the error that stopped Next, if any
+func (c *Cursor[T]) Err() error {
+	return c.err
+}
+
+// Close closes the rows of the cursor, it is safe to call more than once
+func (c *Cursor[T]) Close() error {
+	if c.done {
+		return nil
+	}
+	c.done = true
+	c.pending = false
+	return c.rows.Close()
+}
+
+func (c *Cursor[T]) fail(err error) bool {
+	c.err = err
+	c.Close()
+	return false
+}
+
+// Iter returns a sequence over the rows of the query that can be ranged
+// over, the query is executed when the sequence is iterated and its rows
+// are closed when the loop ends, including when it breaks early
+// e.g.
+//
+//	for user, err := range goorm.Iter[User](ctx, qb.Select().From("users")) {
+//		if err != nil {
+//			return err
+//		}
+//	}
+func Iter[T any](ctx context.Context, q *QueryBuilder) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+		cursor, err := NewCursor[T](ctx, q)
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+		defer cursor.Close()
+
+		for cursor.Next() {
+			if !yield(cursor.Value(), nil) {
+				return
+			}
+		}
+		if err := cursor.Err(); err != nil {
+			yield(zero, err)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package goorm
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Expression is a piece of SQL with its own parameters, numbered from 1,
+// which the builder embeds with its placeholders numbered after the ones
+// of the query
+type Expression interface {
+	ToSQL(dialect Dialect) (string, []any)
+}
+
+// rawExpr is SQL embedded as is
+type rawExpr struct {
+	sql  string
+	args []any
+}
+
+// Expr is raw SQL the builder embeds as is, without prefixing or quoting
+// its columns. Its placeholders are numbered from 1, or written ?, and
+// renumbered after the parameters of the query
+// e.g. Where(goorm.Expr("COALESCE(nickname, name) = $1", name)) or
+// Select(goorm.Expr("id::text AS key"))
+func Expr(sql string, args ...any) Expression {
+	return rawExpr{sql: sql, args: args}
+}
+
+func (e rawExpr) ToSQL(dialect Dialect) (string, []any) {
+	return bindPlaceholders(dialect, e.sql, 0, len(e.args)), e.args
+}
+
+// Ident is an identifier, a table or column name which can be qualified
+// e.g. Ident("orders.user"), always quoted with Dialect.Quote
+type Ident string
+
+func (i Ident) ToSQL(dialect Dialect) (string, []any) {
+	return quoteIdentifier(dialect, string(i)), nil
+}
+
+// alias is an expression or subquery named with AS
+type alias struct {
+	value any
+	name  string
+}
+
+// As names a subquery or expression in a SELECT
+// e.g.
+//
+//	count := goorm.NewQueryBuilder(db, dialect, nil).Select("COUNT(*)").From("posts").Where("posts.user_id = users.id")
+//	qb.Select("id", goorm.As(count, "post_count")).From("users")
+func As(value any, name string) Expression {
+	return alias{value: value, name: name}
+}
+
+func (a alias) ToSQL(dialect Dialect) (string, []any) {
+	query, args, _ := toSQL(dialect, a.value)
+	return query + " AS " + a.name, args
+}
+
+// toSQL renders a subquery, in parentheses, or an expression with its
+// placeholders numbered from 1, the subquery builder is reset
+func toSQL(dialect Dialect, value any) (string, []any, error) {
+	switch v := value.(type) {
+	case *QueryBuilder:
+		if err := v.err; err != nil {
+			v.Reset()
+			return "", nil, err
+		}
+		query, args := v.build()
+		v.Reset()
+		return "(" + strings.TrimSuffix(strings.TrimSpace(query), ";") + ")", args, nil
+	case alias:
+		query, args, err := toSQL(dialect, v.value)
+		return query + " AS " + v.name, args, err
+	case *WindowFunc:
+		if !supportsWindow(dialect) {
+			return "", nil, fmt.Errorf("%s does not support window functions", dialectName(dialect))
+		}
+		query, args := v.ToSQL(dialect)
+		return query, args, nil
+	case Expression:
+		query, args := v.ToSQL(dialect)
+		return query, args, nil
+	case string:
+		return v, nil, nil
+	default:
+		return "", nil, fmt.Errorf("unsupported expression %T", value)
+	}
+}
+
+// embed returns the SQL of a subquery or expression with its placeholders
+// numbered after the parameters of the query and adds its parameters
+func (q *QueryBuilder) embed(value any) string {
+	query, args, err := toSQL(q.Dialect, value)
+	if err != nil {
+		q.err = err
+		return ""
+	}
+	query = shiftPlaceholders(query, len(q.params))
+	q.params = append(q.params, args...)
+	q.embedded += len(args)
+	return query
+}
+
+// embedQuery works like embed but always encloses the SQL in parentheses
+func (q *QueryBuilder) embedQuery(value any) string {
+	query := q.embed(value)
+	if _, ok := value.(*QueryBuilder); ok {
+		return query
+	}
+	return "(" + query + ")"
+}
+
+// isQuery reports whether a value is a subquery or an expression rather
+// than a plain value
+func isQuery(value any) bool {
+	switch value.(type) {
+	case *QueryBuilder, Expression:
+		return true
+	}
+	return false
+}
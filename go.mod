@@ -1,6 +1,6 @@
 module github.com/patrickkabwe/goorm
 
-go 1.22.5
+go 1.23
 
 require (
 	github.com/jackc/pgx/v5 v5.7.1
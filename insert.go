@@ -0,0 +1,151 @@
+package goorm
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// valuesSQL renders the VALUES clause of the rows, numbering placeholders
+// after offset bound parameters
+func valuesSQL(dialect Dialect, offset int, rows [][]any) string {
+	var b strings.Builder
+	b.WriteString(" VALUES ")
+	n := offset
+	for i, row := range rows {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString("(")
+		for j := range row {
+			if j > 0 {
+				b.WriteString(", ")
+			}
+			n++
+			b.WriteString(dialect.GetPlaceholder(n))
+		}
+		b.WriteString(")")
+	}
+	return b.String()
+}
+
+// batches splits the rows to insert so that every statement binds no more
+// parameters than the dialect allows
+func (q *QueryBuilder) batches() [][][]any {
+	if len(q.rows) < 2 || len(q.rows[0]) == 0 {
+		return nil
+	}
+
+	// every batch binds the parameters of the common table expressions and
+	// the ones outside of VALUES, such as those of the conflict clause
+	reserved := len(q.params) - len(q.rows)*len(q.rows[0])
+	for _, c := range q.ctes {
+		reserved += len(c.params)
+	}
+	size := (maxParams(q.Dialect) - reserved) / len(q.rows[0])
+	if size < 1 || len(q.rows) <= size {
+		return [][][]any{q.rows}
+	}
+
+	var batches [][][]any
+	for start := 0; start < len(q.rows); start += size {
+		end := min(start+size, len(q.rows))
+		batches = append(batches, q.rows[start:end])
+	}
+	return batches
+}
+
+// insertBatch returns a builder of tx inserting rows with the rest of the
+// query
+func (q *QueryBuilder) insertBatch(tx *QueryBuilder, rows [][]any) *QueryBuilder {
+	sub := tx.fork()
+	sub.query.WriteString(q.query.String())
+	sub.params = append(sub.params, q.params[:q.valuesOffset]...)
+	sub.valuesOffset = q.valuesOffset
+	for _, row := range rows {
+		sub.rows = append(sub.rows, row)
+		sub.params = append(sub.params, row...)
+	}
+	sub.params = append(sub.params, q.params[q.valuesOffset+len(q.rows)*len(q.rows[0]):]...)
+	sub.columns = q.columns
+	sub.conflict = q.conflict
+	sub.returning = q.returning
+	sub.currentTable = q.currentTable
+	sub.ctes = q.ctes
+	return sub
+}
+
+// execBatches runs one insert per batch and returns the rows of the last
+// one. The batches run in a transaction so that they are inserted together,
+// the one of the builder when it has one.
+func (q *QueryBuilder) execBatches(ctx context.Context, batches [][][]any) (*sql.Rows, error) {
+	defer q.Reset()
+
+	var rows *sql.Rows
+	err := q.Transaction(ctx, func(tx *QueryBuilder) error {
+		for i, batch := range batches {
+			var err error
+			if rows, err = q.insertBatch(tx, batch).Exec(ctx); err != nil {
+				return err
+			}
+			// committing closes the rows of the transaction
+			if i < len(batches)-1 || tx.tx != q.tx {
+				if err := rows.Close(); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// scanBatches runs one insert per batch, in a transaction like execBatches,
+// and appends the returned rows of every batch to model
+func (q *QueryBuilder) scanBatches(ctx context.Context, batches [][][]any, model interface{}, mode MappingMode) error {
+	defer q.Reset()
+
+	target := reflect.ValueOf(model)
+	if target.Kind() != reflect.Pointer {
+		return fmt.Errorf("model must be a pointer")
+	}
+	target = target.Elem()
+
+	isSlice := target.Kind() == reflect.Slice && !isScalarType(target.Type())
+	if isSlice {
+		target.Set(reflect.MakeSlice(target.Type(), 0, 0))
+	}
+
+	return q.Transaction(ctx, func(tx *QueryBuilder) error {
+		for i, batch := range batches {
+			rows, err := q.insertBatch(tx, batch).Exec(ctx)
+			if err != nil {
+				return err
+			}
+
+			if !isSlice {
+				// a single model holds the first returned row
+				if i > 0 {
+					rows.Close()
+					continue
+				}
+				if err := q.mapToModel(rows, model, mode); err != nil {
+					return err
+				}
+				continue
+			}
+
+			dest := reflect.New(target.Type())
+			if err := q.mapToModel(rows, dest.Interface(), mode); err != nil {
+				return err
+			}
+			target.Set(reflect.AppendSlice(target, dest.Elem()))
+		}
+		return nil
+	})
+}
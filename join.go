@@ -0,0 +1,212 @@
+package goorm
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TableRef is a table, or a subquery or expression, to join with an
+// optional alias e.g. Ref("profiles").As("p")
+type TableRef struct {
+	source  any
+	alias   string
+	lateral bool
+}
+
+// Ref refers to a table by name, which can include its schema
+func Ref(name string) *TableRef {
+	return &TableRef{source: name}
+}
+
+// Lateral joins a subquery that can reference the columns of the tables
+// before it e.g.
+//
+//	latest := goorm.NewQueryBuilder(db, dialect, nil).Select("body").From("posts").
+//		Where("posts.user_id = users.id").OrderBy("id DESC").Limit(1)
+//	qb.Select("users.name", "p.body").From("users").LeftJoin(goorm.Lateral(latest).As("p"), goorm.OnTrue())
+func Lateral(sub any) *TableRef {
+	return &TableRef{source: sub, lateral: true}
+}
+
+// As sets the alias of the table, conditions referring to the table by
+// name are rewritten to use the alias
+func (t *TableRef) As(alias string) *TableRef {
+	t.alias = alias
+	return t
+}
+
+// JoinCondition is the ON or USING clause of a join
+type JoinCondition struct {
+	conditions []string
+	args       []any
+	using      []string
+}
+
+// On joins the rows where the columns are equal e.g. On("users.id", "p.user_id"),
+// further conditions are added with And and Or
+func On(left string, right string) *JoinCondition {
+	return &JoinCondition{conditions: []string{left + " = " + right}}
+}
+
+// OnTrue joins every row, as LATERAL joins usually do
+func OnTrue() *JoinCondition {
+	return &JoinCondition{conditions: []string{"TRUE"}}
+}
+
+// Using joins the rows where the columns of the same name are equal
+func Using(columns ...string) *JoinCondition {
+	return &JoinCondition{using: columns}
+}
+
+// And adds a condition whose placeholders are numbered from 1 across the
+// conditions of the join e.g. And("p.kind = $1", "public")
+func (c *JoinCondition) And(condition string, args ...any) *JoinCondition {
+	return c.add("AND", condition, args)
+}
+
+// Or adds a condition like And joined with OR
+func (c *JoinCondition) Or(condition string, args ...any) *JoinCondition {
+	return c.add("OR", condition, args)
+}
+
+func (c *JoinCondition) add(operator string, condition string, args []any) *JoinCondition {
+	c.conditions = append(c.conditions, operator, condition)
+	c.args = append(c.args, args...)
+	return c
+}
+
+// FullJoin keeps the rows of both tables, joined where the condition holds
+func (q *QueryBuilder) FullJoin(table any, condition any) *QueryBuilder {
+	if q.Dialect.GetName() == Mysql {
+		q.err = fmt.Errorf("%s does not support FULL JOIN", dialectName(q.Dialect))
+		return q
+	}
+	return q.Join("FULL", table, condition)
+}
+
+// CrossJoin joins every row of the query with every row of table
+func (q *QueryBuilder) CrossJoin(table any) *QueryBuilder {
+	return q.Join("CROSS", table, nil)
+}
+
+// joinSource renders the table of a join and records its alias
+func (q *QueryBuilder) joinSource(table any) string {
+	switch t := table.(type) {
+	case string:
+		// the alias is the last word e.g. "profiles p" or "profiles AS p"
+		if parts := strings.Fields(t); len(parts) > 1 {
+			q.setAlias(parts[0], parts[len(parts)-1])
+		}
+		return q.quoteAliased(t)
+	case *TableRef:
+		var source string
+		if name, ok := t.source.(string); ok {
+			source = q.quoteName(name)
+			if t.alias != "" {
+				q.setAlias(name, t.alias)
+			}
+		} else {
+			source = q.embedQuery(t.source)
+		}
+		if t.lateral {
+			if !supportsLateral(q.Dialect) {
+				q.err = fmt.Errorf("%s does not support LATERAL joins", dialectName(q.Dialect))
+			}
+			source = "LATERAL " + source
+		}
+		if t.alias != "" {
+			source += " AS " + q.quoteName(t.alias)
+		}
+		return source
+	default:
+		return q.embedQuery(table)
+	}
+}
+
+// joinCondition renders the ON or USING clause of a join with its
+// parameters numbered after the ones of the query
+func (q *QueryBuilder) joinCondition(condition any) string {
+	switch c := condition.(type) {
+	case nil:
+		return ""
+	case string:
+		return " ON " + q.qualifyCondition(c)
+	case *JoinCondition:
+		if len(c.using) > 0 {
+			columns := make([]string, len(c.using))
+			for i, column := range c.using {
+				columns[i] = q.quoteName(column)
+			}
+			return " USING (" + strings.Join(columns, ", ") + ")"
+		}
+		parts := make([]string, len(c.conditions))
+		for i, part := range c.conditions {
+			parts[i] = q.qualifyCondition(part)
+		}
+		sql := shiftPlaceholders(strings.Join(parts, " "), len(q.params))
+		q.params = append(q.params, c.args...)
+		q.embedded += len(c.args)
+		return " ON " + sql
+	default:
+		q.err = fmt.Errorf("unsupported join condition %T", condition)
+		return ""
+	}
+}
+
+// setAlias records the alias of a table and rewrites the fields of the
+// SELECT referring to the table by name
+func (q *QueryBuilder) setAlias(table string, alias string) {
+	if q.aliases == nil {
+		q.aliases = make(map[string]string)
+	}
+	q.aliases[tableName(table)] = alias
+
+	if len(q.fields) > 0 {
+		q.renderFields()
+	}
+}
+
+// renderFields writes the fields of the SELECT again, prefixed with the
+// table of the query and qualified with the aliases known so far
+func (q *QueryBuilder) renderFields() {
+	query := q.query.String()
+	list := q.selectList(q.currentTable)
+	q.query.Reset()
+	q.query.WriteString(query[:q.fieldsAt])
+	q.query.WriteString(list)
+	q.query.WriteString(query[q.fieldsEnd:])
+	q.fieldsEnd = q.fieldsAt + len(list)
+}
+
+// tableName returns the name of a table without its schema
+func tableName(table string) string {
+	parts := strings.Split(table, ".")
+	return parts[len(parts)-1]
+}
+
+// qualify rewrites a column qualified by the name of an aliased table to
+// use the alias e.g. profiles.avatar becomes p.avatar
+func (q *QueryBuilder) qualify(column string) string {
+	i := strings.Index(column, ".")
+	if i <= 0 || len(q.aliases) == 0 {
+		return column
+	}
+	if alias, ok := q.aliases[column[:i]]; ok {
+		return alias + column[i:]
+	}
+	return column
+}
+
+// qualifyCondition qualifies and quotes the qualified columns of a condition
+func (q *QueryBuilder) qualifyCondition(condition string) string {
+	if !strings.Contains(condition, ".") {
+		return condition
+	}
+	parts := strings.Fields(condition)
+	for i, part := range parts {
+		if strings.Contains(part, ".") {
+			parts[i] = q.quoteName(q.qualify(part))
+		}
+	}
+	return strings.Join(parts, " ")
+}
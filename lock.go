@@ -0,0 +1,113 @@
+package goorm
+
+import (
+	"fmt"
+	"strings"
+)
+
+// lockClause is the row locking clause of a SELECT
+type lockClause struct {
+	strength string
+	of       []string
+	wait     string
+}
+
+// ForUpdate locks the selected rows against updates and other locks until
+// the transaction ends, it belongs in a Transaction as the locks of a
+// statement run outside one are released once it completes. SQLite locks
+// the whole database in a transaction, so the locking clauses do nothing.
+// e.g.
+//
+//	err := qb.Transaction(ctx, func(tx *goorm.QueryBuilder) error {
+//		return tx.Select().From("jobs").Where("status = $1", "queued").
+//			Limit(10).ForUpdate().SkipLocked().Scan(ctx, &jobs)
+//	})
+func (q *QueryBuilder) ForUpdate() *QueryBuilder {
+	return q.setLock("UPDATE")
+}
+
+// ForShare locks the selected rows against updates until the transaction
+// ends, other transactions can still share the lock
+func (q *QueryBuilder) ForShare() *QueryBuilder {
+	return q.setLock("SHARE")
+}
+
+// SkipLocked leaves out the rows locked by other transactions instead of
+// waiting for them
+func (q *QueryBuilder) SkipLocked() *QueryBuilder {
+	return q.setLockWait("SKIP LOCKED")
+}
+
+// NoWait fails instead of waiting for rows locked by other transactions
+func (q *QueryBuilder) NoWait() *QueryBuilder {
+	return q.setLockWait("NOWAIT")
+}
+
+// Of only locks the rows of the tables, or aliases, of a joined query
+// e.g. ForUpdate().Of("jobs")
+func (q *QueryBuilder) Of(tables ...string) *QueryBuilder {
+	if q.lock == nil {
+		q.err = fmt.Errorf("Of requires ForUpdate or ForShare")
+		return q
+	}
+	if d, ok := q.Dialect.(*MYSQL); ok && !versionAtLeast(d.Version, "8.0") {
+		q.err = fmt.Errorf("%s does not support FOR %s OF", dialectName(q.Dialect), q.lock.strength)
+		return q
+	}
+	q.lock.of = append(q.lock.of, tables...)
+	return q
+}
+
+func (q *QueryBuilder) setLock(strength string) *QueryBuilder {
+	if q.lock == nil {
+		q.lock = &lockClause{}
+	}
+	q.lock.strength = strength
+	return q
+}
+
+func (q *QueryBuilder) setLockWait(wait string) *QueryBuilder {
+	if q.lock == nil {
+		q.err = fmt.Errorf("%s requires ForUpdate or ForShare", wait)
+		return q
+	}
+	if d, ok := q.Dialect.(*MYSQL); ok && !versionAtLeast(d.Version, "8.0") {
+		q.err = fmt.Errorf("%s does not support %s", dialectName(q.Dialect), wait)
+		return q
+	}
+	q.lock.wait = wait
+	return q
+}
+
+// lockSQL renders the locking clause, MySQL before 8.0 only has FOR UPDATE
+// and LOCK IN SHARE MODE
+func (q *QueryBuilder) lockSQL() string {
+	switch d := q.Dialect.(type) {
+	case *SQLite:
+		return ""
+	case *MYSQL:
+		if !versionAtLeast(d.Version, "8.0") && q.lock.strength == "SHARE" {
+			return " LOCK IN SHARE MODE"
+		}
+	}
+
+	query := " FOR " + q.lock.strength
+	if len(q.lock.of) > 0 {
+		tables := make([]string, len(q.lock.of))
+		for i, table := range q.lock.of {
+			tables[i] = q.quoteName(table)
+		}
+		query += " OF " + strings.Join(tables, ", ")
+	}
+	if q.lock.wait != "" {
+		query += " " + q.lock.wait
+	}
+	return query
+}
+
+// checkLock warns about locking clauses outside of a transaction
+func (q *QueryBuilder) checkLock() {
+	if _, sqlite := q.Dialect.(*SQLite); q.lock != nil && q.tx == nil && !sqlite {
+		q.logger.Warn(fmt.Sprintf("FOR %s outside of a transaction releases its locks when the statement completes", q.lock.strength))
+	}
+}
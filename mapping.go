@@ -0,0 +1,139 @@
+package goorm
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// MappingMode controls how Scan reports result set columns that match no
+// struct field and struct fields that match no column
+type MappingMode int
+
+const (
+	// MappingSilent ignores unmapped columns and unfilled fields
+	MappingSilent MappingMode = iota + 1
+	// MappingLenient logs unmapped columns and unfilled fields with Logger.Warn
+	MappingLenient
+	// MappingStrict fails the scan with a *MappingError
+	MappingStrict
+)
+
+// MappingError lists the columns and fields that could not be mapped
+type MappingError struct {
+	Model   string
+	Columns []string
+	Fields  []string
+}
+
+func (e *MappingError) Error() string {
+	var problems []string
+	if len(e.Columns) > 0 {
+		problems = append(problems, "unmapped columns: "+strings.Join(e.Columns, ", "))
+	}
+	if len(e.Fields) > 0 {
+		problems = append(problems, "unfilled fields: "+strings.Join(e.Fields, ", "))
+	}
+	return fmt.Sprintf("mapping %s: %s", e.Model, strings.Join(problems, "; "))
+}
+
+// ConversionError reports a column value that cannot be stored in a field
+// without losing information, e.g. 1.5 or 300 into an int8
+type ConversionError struct {
+	Value  any
+	Type   reflect.Type
+	Reason string
+	// Err is the error parsing a text value
+	Err error
+}
+
+func (e *ConversionError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("converting %T %v to %s: %v", e.Value, e.Value, e.Type, e.Err)
+	}
+	return fmt.Sprintf("converting %T %v to %s: value %s", e.Value, e.Value, e.Type, e.Reason)
+}
+
+func (e *ConversionError) Unwrap() error {
+	return e.Err
+}
+
+// Strict makes Scan and Returning of the current query fail with a
+// *MappingError when a column matches no field or a field matches no column
+func (q *QueryBuilder) Strict() *QueryBuilder {
+	q.mapping = MappingStrict
+	return q
+}
+
+// Lenient makes Scan and Returning of the current query log columns that
+// match no field and fields that match no column with Logger.Warn
+func (q *QueryBuilder) Lenient() *QueryBuilder {
+	q.mapping = MappingLenient
+	return q
+}
+
+// SetMappingMode sets the MappingMode of queries that call neither Strict
+// nor Lenient, MappingSilent by default
+func (q *QueryBuilder) SetMappingMode(mode MappingMode) *QueryBuilder {
+	q.defaultMapping = mode
+	return q
+}
+
+func (q *QueryBuilder) mappingMode() MappingMode {
+	if q.mapping != 0 {
+		return q.mapping
+	}
+	if q.defaultMapping != 0 {
+		return q.defaultMapping
+	}
+	return MappingSilent
+}
+
+// mappingTracker records the columns and fields used while mapping a row,
+// a nil tracker records nothing
+type mappingTracker struct {
+	used     map[string]bool
+	unfilled []string
+}
+
+func newMappingTracker() *mappingTracker {
+	return &mappingTracker{used: make(map[string]bool)}
+}
+
+func (t *mappingTracker) use(column string) {
+	if t != nil {
+		t.used[column] = true
+	}
+}
+
+func (t *mappingTracker) miss(field string) {
+	if t != nil {
+		t.unfilled = append(t.unfilled, field)
+	}
+}
+
+// reportMapping returns or logs the columns and fields the tracker did not
+// see being mapped depending on the mode
+func (q *QueryBuilder) reportMapping(model reflect.Type, columns []string, tracker *mappingTracker, mode MappingMode) error {
+	var unmapped []string
+	for _, column := range columns {
+		if !tracker.used[column] {
+			unmapped = append(unmapped, column)
+		}
+	}
+	if len(unmapped) == 0 && len(tracker.unfilled) == 0 {
+		return nil
+	}
+
+	err := &MappingError{
+		Model:   model.Name(),
+		Columns: unmapped,
+		Fields:  tracker.unfilled,
+	}
+	if mode == MappingStrict {
+		return err
+	}
+
+	q.logger.Warn(err.Error(), "columns", unmapped, "fields", tracker.unfilled)
+	return nil
+}
@@ -0,0 +1,198 @@
+package goorm
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+)
+
+var valuerType = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+
+// columnFields returns the fields holding a column value, relations and
+// nested structs are left out
+func (s *schema) columnFields() []*field {
+	var fields []*field
+	for _, f := range s.Fields {
+		if isScalarType(f.Type) || f.Type.Implements(valuerType) {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+// isGenerated reports whether the database generates the value of a field
+// when it is not inserted: the primary key and fields tagged with
+// auto_increment or a default
+func (s *schema) isGenerated(f *field) bool {
+	if f == s.PrimaryKey {
+		return true
+	}
+	for _, option := range []string{"auto_increment", "autoincrement", "default"} {
+		if _, ok := f.Options[option]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Model inserts the fields of a struct, or of a slice of structs, mapped to
+// columns by their db tags. Zero fields the database generates, the primary
+// key and fields tagged auto_increment or default, are left out so that
+// Save writes the generated values back into the model. Zero fields tagged
+// omitempty are left out as well.
+// e.g.
+//
+//	err := qb.InsertInto("users").Model(&user).Save(ctx)
+func (q *QueryBuilder) Model(model any) *QueryBuilder {
+	v := reflect.ValueOf(model)
+	if v.Kind() != reflect.Pointer || v.IsNil() {
+		q.err = fmt.Errorf("model must be a pointer")
+		return q
+	}
+
+	rows := modelStructs(v)
+	if len(rows) == 0 {
+		q.err = fmt.Errorf("model has nothing to insert")
+		return q
+	}
+	s := schemaOf(rows[0].Type())
+
+	var columns []any
+	var fields []*field
+	for _, f := range s.columnFields() {
+		zero := true
+		for _, row := range rows {
+			zero = zero && row.Field(f.Index).IsZero()
+		}
+
+		if zero && s.isGenerated(f) {
+			q.generated = append(q.generated, f.Column)
+			continue
+		}
+		if _, ok := f.Options["omitempty"]; ok && zero {
+			continue
+		}
+		columns = append(columns, f.Column)
+		fields = append(fields, f)
+	}
+
+	q.Columns(columns...)
+	for _, row := range rows {
+		values := make([]any, len(fields))
+		for i, f := range fields {
+			values[i] = row.Field(f.Index).Interface()
+		}
+		q.Values(values...)
+	}
+	q.model = model
+	return q
+}
+
+// SetModel sets the columns of the named fields, given by Go or column
+// name, to the values of the struct. Without fields every non zero field
+// but the primary key is set.
+// e.g.
+//
+//	err := qb.Update("users").SetModel(&user, "Name", "Email").Where("id = $3", user.ID).Save(ctx)
+func (q *QueryBuilder) SetModel(model any, fields ...string) *QueryBuilder {
+	v := reflect.ValueOf(model)
+	if v.Kind() != reflect.Pointer || v.Elem().Kind() != reflect.Struct {
+		q.err = fmt.Errorf("model must be a pointer to a struct")
+		return q
+	}
+	v = v.Elem()
+	s := schemaOf(v.Type())
+
+	var set []*field
+	if len(fields) > 0 {
+		for _, name := range fields {
+			f := s.lookup(name)
+			if f == nil {
+				q.err = fmt.Errorf("%s has no field %s", s.Type.Name(), name)
+				return q
+			}
+			set = append(set, f)
+		}
+	} else {
+		for _, f := range s.columnFields() {
+			if f != s.PrimaryKey && !v.Field(f.Index).IsZero() {
+				set = append(set, f)
+			}
+		}
+	}
+	if len(set) == 0 {
+		q.err = fmt.Errorf("%s has no fields to set", s.Type.Name())
+		return q
+	}
+
+	for _, f := range set {
+		q.Set(f.Column, v.Field(f.Index).Interface())
+	}
+	q.model = model
+	return q
+}
+
+// Save executes the query built with Model or SetModel and writes the
+// values the database generated back into the model, using RETURNING or
+// a follow-up SELECT on databases without it. The related models held by
+// the relation fields of the model are saved too, in the same transaction:
+// the ones without a primary key are inserted, the others updated, and all
+// of them linked to the model.
+func (q *QueryBuilder) Save(ctx context.Context) error {
+	model := q.model
+	generated := q.generated
+	if model == nil && q.err == nil {
+		q.Reset()
+		return fmt.Errorf("Save requires Model or SetModel")
+	}
+
+	models := modelStructs(reflect.ValueOf(model))
+	if q.err == nil && q.tx == nil && hasLoadedRelations(models) {
+		return q.Transaction(ctx, func(tx *QueryBuilder) error {
+			q.moveTo(tx)
+			return tx.Save(ctx)
+		})
+	}
+
+	var pending []*Association
+	if q.err == nil {
+		var err error
+		if pending, err = q.saveBelongsTo(ctx, models); err != nil {
+			q.Reset()
+			return err
+		}
+	}
+
+	if len(generated) == 0 {
+		rows, err := q.Exec(ctx)
+		if err != nil {
+			return err
+		}
+		if err := rows.Close(); err != nil {
+			return err
+		}
+		return q.saveAssociations(ctx, models, pending)
+	}
+
+	// the returned rows only hold the generated columns, they are copied
+	// into the model once scanned
+	q.returning = append(q.returning, generated...)
+	dest := reflect.New(reflect.TypeOf(model).Elem())
+	if err := q.exec(ctx, dest.Interface()); err != nil {
+		return err
+	}
+
+	returned := modelStructs(dest)
+	if len(returned) != len(models) {
+		return fmt.Errorf("save: %d rows returned for %d models", len(returned), len(models))
+	}
+	s := schemaOf(models[0].Type())
+	for i := range models {
+		for _, column := range generated {
+			f := s.columns[column]
+			models[i].Field(f.Index).Set(returned[i].Field(f.Index))
+		}
+	}
+	return q.saveAssociations(ctx, models, pending)
+}
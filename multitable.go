@@ -0,0 +1,84 @@
+package goorm
+
+import (
+	"fmt"
+	"strings"
+)
+
+// addSource adds a table an UPDATE reads from or a DELETE joins with,
+// rendered by sourcesSQL in the syntax of the dialect
+func (q *QueryBuilder) addSource(operation string, table any, alias ...string) *QueryBuilder {
+	switch d := q.Dialect.(type) {
+	case *SQLite:
+		if operation == "DELETE" {
+			q.err = fmt.Errorf("%s does not support DELETE with USING", dialectName(q.Dialect))
+			return q
+		}
+		if !versionAtLeast(d.Version, "3.33") {
+			q.err = fmt.Errorf("%s does not support UPDATE with FROM", dialectName(q.Dialect))
+			return q
+		}
+	}
+
+	if len(alias) > 0 && alias[0] != "" {
+		ref, ok := table.(*TableRef)
+		if !ok {
+			ref = &TableRef{source: table}
+		}
+		table = ref.As(alias[0])
+	}
+	start := len(q.params)
+	q.sources = append(q.sources, q.joinSource(table))
+	if q.Dialect.GetPlaceholder(1) == "?" {
+		// the placeholders are bound in the order of the query, where
+		// sourcesSQL renders the sources
+		q.sourceParams = append(q.sourceParams, q.params[start:]...)
+		q.params = q.params[:start]
+	}
+	return q
+}
+
+// Using adds tables a DELETE joins with, its conditions go in Where e.g.
+//
+//	qb.Delete("sessions").Using("users u").Where("sessions.user_id = u.id AND u.active = $1", false)
+//
+// renders DELETE FROM sessions USING users u WHERE ... on PostgreSQL and
+// DELETE sessions FROM sessions JOIN users u WHERE ... on MySQL, SQLite
+// does not support it
+func (q *QueryBuilder) Using(tables ...any) *QueryBuilder {
+	if !hasOperation(q.operations, "DELETE") {
+		q.err = fmt.Errorf("Using requires Delete")
+		return q
+	}
+	for _, table := range tables {
+		q.addSource("DELETE", table)
+	}
+	return q
+}
+
+// sourcesSQL adds the tables of an UPDATE with From or a DELETE with Using
+// to the query: UPDATE ... SET ... FROM and DELETE ... USING on PostgreSQL
+// and SQLite, and multi-table statements with JOIN on MySQL
+func (q *QueryBuilder) sourcesSQL(query string) string {
+	mysql := q.Dialect.GetName() == Mysql
+	switch {
+	case hasOperation(q.operations, "UPDATE") && mysql:
+		return query[:q.tableEnd] + " JOIN " + strings.Join(q.sources, " JOIN ") + query[q.tableEnd:]
+	case hasOperation(q.operations, "UPDATE"):
+		at := q.setEnd
+		if at == 0 {
+			at = len(query)
+		}
+		return query[:at] + " FROM " + strings.Join(q.sources, ", ") + query[at:]
+	case mysql:
+		target := strings.TrimPrefix(query[:q.tableEnd], "DELETE FROM ")
+		table := q.currentTable
+		if alias, ok := q.aliases[table]; ok {
+			// the rows are deleted from the table of the alias
+			table = alias
+		}
+		return "DELETE " + q.quoteName(table) + " FROM " + target + " JOIN " + strings.Join(q.sources, " JOIN ") + query[q.tableEnd:]
+	default:
+		return query[:q.tableEnd] + " USING " + strings.Join(q.sources, ", ") + query[q.tableEnd:]
+	}
+}
@@ -7,7 +7,11 @@ import (
 )
 
 // MYSQL dialect
-type MYSQL struct{}
+type MYSQL struct {
+	// Version is the server version e.g. 8.0.36, features of the latest
+	// version are used when it is empty
+	Version string
+}
 
 func (m *MYSQL) GetName() Driver {
 	return "mysql"
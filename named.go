@@ -0,0 +1,123 @@
+package goorm
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Raw sets the query to raw SQL, whose parameters are positional
+// e.g. Raw("SELECT * FROM users WHERE id = $1", 1) or named after the keys
+// of a map or the columns of a struct
+// e.g.
+//
+//	err := qb.Raw("SELECT * FROM users WHERE email = :email AND org_id = :org",
+//		map[string]any{"email": email, "org": orgID}).Scan(ctx, &users)
+func (q *QueryBuilder) Raw(query string, args ...any) *QueryBuilder {
+	if isNamedArg(args) {
+		sql, values, err := bindNamed(q.Dialect, query, len(q.params), args[0])
+		if err != nil {
+			q.err = err
+			return q
+		}
+		query = sql
+		args = values
+	} else {
+		query = q.bindPlaceholders(query, len(args))
+	}
+
+	q.query.WriteString(query)
+	q.params = append(q.params, args...)
+	return q
+}
+
+// isNamedArg reports whether the arguments are a single map or struct
+// holding the values of named parameters
+func isNamedArg(args []any) bool {
+	if len(args) != 1 {
+		return false
+	}
+	if _, ok := args[0].(map[string]any); ok {
+		return true
+	}
+	t := reflect.TypeOf(args[0])
+	if t == nil || t.Implements(valuerType) {
+		return false
+	}
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct && !isScalarType(t)
+}
+
+// namedValue returns a function looking up the value of a named parameter
+// in a map or in the fields of a struct by column or field name
+func namedValue(arg any) func(name string) (any, bool) {
+	if m, ok := arg.(map[string]any); ok {
+		return func(name string) (any, bool) {
+			value, ok := m[name]
+			return value, ok
+		}
+	}
+
+	v := reflect.Indirect(reflect.ValueOf(arg))
+	s := schemaOf(v.Type())
+	return func(name string) (any, bool) {
+		f := s.lookup(name)
+		if f == nil {
+			return nil, false
+		}
+		return v.Field(f.Index).Interface(), true
+	}
+}
+
+// bindNamed rewrites the :name parameters of a query to placeholders of the
+// dialect numbered after offset and returns their values, a name used more
+// than once is bound once per use. Quoted text and PostgreSQL casts such as
+// id::text are left as is.
+func bindNamed(dialect Dialect, query string, offset int, arg any) (string, []any, error) {
+	lookup := namedValue(arg)
+
+	var b strings.Builder
+	var values []any
+	var quote byte
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == ':' && i+1 < len(query) && query[i+1] == ':':
+			b.WriteString("::")
+			i++
+			continue
+		case c == ':' && i+1 < len(query) && isNameStart(query[i+1]):
+			j := i + 1
+			for j < len(query) && isNamePart(query[j]) {
+				j++
+			}
+			name := query[i+1 : j]
+			value, ok := lookup(name)
+			if !ok {
+				return "", nil, fmt.Errorf("missing value for named parameter %s", name)
+			}
+			values = append(values, value)
+			b.WriteString(dialect.GetPlaceholder(offset + len(values)))
+			i = j - 1
+			continue
+		}
+		b.WriteByte(c)
+	}
+	return b.String(), values, nil
+}
+
+func isNameStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isNamePart(c byte) bool {
+	return isNameStart(c) || (c >= '0' && c <= '9')
+}
@@ -0,0 +1,276 @@
+package goorm
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Page is a page of models returned by Paginate
+type Page[T any] struct {
+	Items      []T
+	Total      int64
+	Page       int
+	PerPage    int
+	TotalPages int
+}
+
+// CursorPage is a page of models returned by CursorPaginate, NextCursor
+// is passed as after to fetch the following page
+type CursorPage[T any] struct {
+	Items      []T
+	NextCursor string
+	HasMore    bool
+}
+
+// Paginate returns the page of the query starting at 1 together with the
+// total number of rows, which is counted by running the same query wrapped
+// in SELECT COUNT(*). The query must not have a LIMIT, OFFSET or locking
+// clause, Paginate adds LIMIT and OFFSET after the other clauses.
+// e.g.
+//
+//	page, err := goorm.Paginate[User](ctx, qb.Select().From("users").OrderBy("id"), 2, 20)
+func Paginate[T any](ctx context.Context, q *QueryBuilder, page int, perPage int) (*Page[T], error) {
+	snap, err := q.snapshot()
+	if err != nil {
+		return nil, err
+	}
+	if err := snap.check("paginate", "LIMIT", "OFFSET"); err != nil {
+		return nil, err
+	}
+	if page < 1 || perPage < 1 {
+		return nil, fmt.Errorf("paginate: page and perPage must be greater than 0")
+	}
+
+	result := &Page[T]{Page: page, PerPage: perPage}
+
+	count := q.fork()
+	count.query.WriteString("SELECT COUNT(*) FROM (" + withoutOrderBy(snap.query) + ") AS paginate_count")
+	count.params = append(count.params, snap.params...)
+	if err := count.Scan(ctx, &result.Total); err != nil {
+		return nil, err
+	}
+	result.TotalPages = int((result.Total + int64(perPage) - 1) / int64(perPage))
+
+	offset := (page - 1) * perPage
+	if int64(offset) >= result.Total {
+		return result, nil
+	}
+
+	items := q.fork()
+	snap.replay(items)
+	items.query.WriteString(" LIMIT " + strconv.Itoa(perPage) + " OFFSET " + strconv.Itoa(offset))
+	if err := items.Scan(ctx, &result.Items); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// CursorPaginate returns up to limit models following the cursor after,
+// which is empty for the first page. Rows are ordered by orderBy e.g.
+// "created_at DESC", the primary key of T is added as the last column so
+// that rows with equal values are neither skipped nor repeated. The cursor
+// encodes the values of these columns of the last model of the page.
+//
+// The query must not have a GROUP BY, HAVING, WINDOW, ORDER BY, LIMIT,
+// OFFSET or locking clause nor set operations, which fail with an error,
+// and OR conditions must be enclosed in parentheses.
+// e.g.
+//
+//	page, err := goorm.CursorPaginate[Post](ctx, qb.Select().From("posts"), after, 20, "created_at DESC")
+func CursorPaginate[T any](ctx context.Context, q *QueryBuilder, after string, limit int, orderBy ...string) (*CursorPage[T], error) {
+	snap, err := q.snapshot()
+	if err != nil {
+		return nil, err
+	}
+	if err := snap.check("paginate", keysetClauses...); err != nil {
+		return nil, err
+	}
+	if limit < 1 {
+		return nil, fmt.Errorf("paginate: limit must be greater than 0")
+	}
+
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("paginate: %s is not a model", t)
+	}
+
+	keys, err := sortKeys(schemaOf(t), snap.table, orderBy)
+	if err != nil {
+		return nil, err
+	}
+
+	sub := q.fork()
+	snap.replay(sub)
+
+	if after != "" {
+		values, err := decodeCursor(after, keys)
+		if err != nil {
+			return nil, err
+		}
+		sub.query.WriteString(snap.clause() + keysetCondition(sub, keys, values))
+	}
+
+	order := make([]string, len(keys))
+	for i, key := range keys {
+		order[i] = sub.quoteName(key.column)
+		if key.desc {
+			order[i] += " DESC"
+		}
+	}
+	sub.query.WriteString(" ORDER BY " + strings.Join(order, ", "))
+	sub.query.WriteString(" LIMIT " + strconv.Itoa(limit+1))
+
+	result := &CursorPage[T]{}
+	if err := sub.Scan(ctx, &result.Items); err != nil {
+		return nil, err
+	}
+
+	if len(result.Items) > limit {
+		result.Items = result.Items[:limit]
+		result.HasMore = true
+	}
+	if result.HasMore {
+		last := reflect.Indirect(reflect.ValueOf(result.Items[len(result.Items)-1]))
+		if result.NextCursor, err = encodeCursor(last, keys); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// sortKey is a column rows are ordered by
+type sortKey struct {
+	column string
+	desc   bool
+	field  *field
+}
+
+// sortKeys parses the ORDER BY columns and adds the primary key as the
+// last one when missing
+func sortKeys(s *schema, table string, orderBy []string) ([]sortKey, error) {
+	var keys []sortKey
+	hasPrimaryKey := false
+	for _, order := range orderBy {
+		parts := strings.Fields(order)
+		if len(parts) == 0 || len(parts) > 2 {
+			return nil, fmt.Errorf("paginate: invalid order %q", order)
+		}
+
+		key := sortKey{column: parts[0]}
+		if len(parts) == 2 {
+			switch strings.ToUpper(parts[1]) {
+			case "ASC":
+			case "DESC":
+				key.desc = true
+			default:
+				return nil, fmt.Errorf("paginate: invalid order %q", order)
+			}
+		}
+
+		name := key.column
+		if i := strings.LastIndex(name, "."); i >= 0 {
+			name = name[i+1:]
+		}
+		f, ok := s.columns[name]
+		if !ok {
+			return nil, fmt.Errorf("paginate: %s has no field for column %s", s.Type.Name(), name)
+		}
+		key.field = f
+		hasPrimaryKey = hasPrimaryKey || f == s.PrimaryKey
+		keys = append(keys, key)
+	}
+
+	if !hasPrimaryKey {
+		if s.PrimaryKey == nil {
+			return nil, fmt.Errorf("paginate: %s has no primary key", s.Type.Name())
+		}
+		column := s.PrimaryKey.Column
+		if table != "" {
+			column = table + "." + column
+		}
+		keys = append(keys, sortKey{column: column, field: s.PrimaryKey})
+	}
+	return keys, nil
+}
+
+// keysetCondition returns the condition selecting the rows following the
+// values in the order of keys e.g. for created_at DESC, id
+// (created_at < $1 OR (created_at = $2 AND id > $3))
+func keysetCondition(q *QueryBuilder, keys []sortKey, values []any) string {
+	var or []string
+	for i, key := range keys {
+		var and []string
+		for j := 0; j < i; j++ {
+			and = append(and, q.quoteName(keys[j].column)+" = "+q.Dialect.GetPlaceholder(len(q.params)+1))
+			q.params = append(q.params, values[j])
+		}
+
+		op := " > "
+		if key.desc {
+			op = " < "
+		}
+		and = append(and, q.quoteName(key.column)+op+q.Dialect.GetPlaceholder(len(q.params)+1))
+		q.params = append(q.params, values[i])
+
+		if len(and) == 1 {
+			or = append(or, and[0])
+		} else {
+			or = append(or, "("+strings.Join(and, " AND ")+")")
+		}
+	}
+	return "(" + strings.Join(or, " OR ") + ")"
+}
+
+// encodeCursor encodes the values of the sort keys of a model
+func encodeCursor(model reflect.Value, keys []sortKey) (string, error) {
+	values := make([]any, len(keys))
+	for i, key := range keys {
+		values[i] = model.Field(key.field.Index).Interface()
+	}
+	data, err := json.Marshal(values)
+	if err != nil {
+		return "", fmt.Errorf("paginate: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// decodeCursor decodes a cursor into values of the field types of the keys
+func decodeCursor(cursor string, keys []sortKey) ([]any, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, fmt.Errorf("paginate: invalid cursor")
+	}
+
+	var raw []json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil || len(raw) != len(keys) {
+		return nil, fmt.Errorf("paginate: invalid cursor")
+	}
+
+	values := make([]any, len(keys))
+	for i, key := range keys {
+		value := reflect.New(key.field.Type)
+		if err := json.Unmarshal(raw[i], value.Interface()); err != nil {
+			return nil, fmt.Errorf("paginate: invalid cursor")
+		}
+		values[i] = value.Elem().Interface()
+	}
+	return values, nil
+}
+
+// withoutOrderBy removes the trailing ORDER BY clause of a query, which
+// does not change the number of rows
+func withoutOrderBy(query string) string {
+	i := strings.LastIndex(strings.ToUpper(query), " ORDER BY ")
+	if i < 0 || strings.Contains(query[i:], ")") || strings.Contains(strings.ToUpper(query[i:]), " LIMIT ") {
+		return query
+	}
+	return query[:i]
+}
@@ -0,0 +1,385 @@
+package goorm
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// preload is a relation to eager load once the query has been scanned
+type preload struct {
+	relation   string
+	conditions []any
+}
+
+type preloadNode struct {
+	name       string
+	conditions []any
+	children   []*preloadNode
+}
+
+// Preload eager loads a relation of the scanned models using one query per
+// relation instead of one query per model. Nested relations are separated
+// by a dot and the optional conditions filter the related rows
+// e.g. Preload("Posts.Comments", "comments.approved = $1", true)
+func (q *QueryBuilder) Preload(relation string, conditions ...any) *QueryBuilder {
+	q.preloads = append(q.preloads, preload{
+		relation:   relation,
+		conditions: conditions,
+	})
+	return q
+}
+
+// preload loads the given relations into the scanned model
+func (q *QueryBuilder) preload(ctx context.Context, model interface{}, preloads []preload) error {
+	if len(preloads) == 0 {
+		return nil
+	}
+	return q.loadRelations(ctx, modelStructs(reflect.ValueOf(model)), buildPreloadTree(preloads))
+}
+
+func buildPreloadTree(preloads []preload) []*preloadNode {
+	var roots []*preloadNode
+	for _, p := range preloads {
+		nodes := &roots
+		var node *preloadNode
+		for _, name := range strings.Split(p.relation, ".") {
+			node = nil
+			for _, n := range *nodes {
+				if n.name == name {
+					node = n
+					break
+				}
+			}
+			if node == nil {
+				node = &preloadNode{name: name}
+				*nodes = append(*nodes, node)
+			}
+			nodes = &node.children
+		}
+		if len(p.conditions) > 0 {
+			node.conditions = p.conditions
+		}
+	}
+	return roots
+}
+
+func (q *QueryBuilder) loadRelations(ctx context.Context, parents []reflect.Value, nodes []*preloadNode) error {
+	if len(parents) == 0 {
+		return nil
+	}
+
+	for _, node := range nodes {
+		rel, err := schemaOf(parents[0].Type()).relation(node.name)
+		if err != nil {
+			return fmt.Errorf("preload: %w", err)
+		}
+
+		var related []reflect.Value
+		var links map[string][]string
+		if rel.Kind == manyToMany {
+			related, links, err = q.loadManyToMany(ctx, parents, rel, node.conditions)
+		} else {
+			related, err = q.loadRelated(ctx, parents, rel, node.conditions)
+		}
+		if err != nil {
+			return err
+		}
+
+		// nested relations are loaded before the related models are
+		// assigned since value fields hold copies of them
+		if err := q.loadRelations(ctx, related, node.children); err != nil {
+			return err
+		}
+
+		if rel.Kind == manyToMany {
+			assignManyToMany(parents, rel, related, links)
+		} else {
+			assignRelated(parents, rel, related)
+		}
+	}
+	return nil
+}
+
+// loadRelated fetches the related models of all parents with a single
+// WHERE ... IN (...) query
+func (q *QueryBuilder) loadRelated(ctx context.Context, parents []reflect.Value, rel *relation, conditions []any) ([]reflect.Value, error) {
+	keyColumn, matchColumn := rel.References, rel.ForeignKey
+	if rel.Kind == belongsTo {
+		keyColumn, matchColumn = rel.ForeignKey, rel.References
+	}
+
+	if !rel.Schema.hasColumn(matchColumn) {
+		return nil, fmt.Errorf("preload: %s has no field for column %s", rel.Schema.Type.Name(), matchColumn)
+	}
+
+	keys, err := columnValues(parents, keyColumn)
+	if err != nil {
+		return nil, fmt.Errorf("preload: %w", err)
+	}
+	return q.findRelated(ctx, rel, matchColumn, keys, conditions)
+}
+
+// loadManyToMany fetches the links of all parents from the join table and
+// then the linked models. links maps the key of a parent to the keys of
+// its related models.
+func (q *QueryBuilder) loadManyToMany(ctx context.Context, parents []reflect.Value, rel *relation, conditions []any) ([]reflect.Value, map[string][]string, error) {
+	keys, err := columnValues(parents, rel.ForeignKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("preload: %w", err)
+	}
+	if len(keys) == 0 {
+		return nil, nil, nil
+	}
+
+	links := make(map[string][]string)
+	var relatedKeys []any
+	seen := make(map[string]bool)
+	for _, chunk := range keyChunks(keys, maxParams(q.Dialect)) {
+		sub := q.fork()
+		rows, err := sub.
+			Select(rel.JoinForeignKey, rel.JoinReferences).
+			From(rel.JoinTable).
+			Where(fmt.Sprintf("%s IN (%s)", rel.JoinForeignKey, placeholders(sub.Dialect, 0, len(chunk))), chunk...).
+			Exec(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		for rows.Next() {
+			var parentKey, relatedKey interface{}
+			if err := rows.Scan(&parentKey, &relatedKey); err != nil {
+				rows.Close()
+				return nil, nil, err
+			}
+			key := linkKey(relatedKey)
+			links[linkKey(parentKey)] = append(links[linkKey(parentKey)], key)
+			if !seen[key] {
+				seen[key] = true
+				relatedKeys = append(relatedKeys, relatedKey)
+			}
+		}
+		if err := rows.Err(); err != nil {
+			rows.Close()
+			return nil, nil, err
+		}
+		if err := rows.Close(); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	related, err := q.findRelated(ctx, rel, rel.References, relatedKeys, conditions)
+	return related, links, err
+}
+
+// findRelated fetches the models of a relation whose column matches one of
+// keys, filtered by the optional preload conditions. The keys are split in
+// as many queries as the parameter limit of the dialect requires.
+func (q *QueryBuilder) findRelated(ctx context.Context, rel *relation, column string, keys []any, conditions []any) ([]reflect.Value, error) {
+	if len(keys) == 0 {
+		return nil, nil
+	}
+
+	var condition string
+	var args []any
+	if len(conditions) > 0 {
+		var ok bool
+		if condition, ok = conditions[0].(string); !ok {
+			return nil, fmt.Errorf("preload: condition of %s must be a string", rel.Name)
+		}
+		args = conditions[1:]
+	}
+
+	var related []reflect.Value
+	for _, chunk := range keyChunks(keys, maxParams(q.Dialect)-len(args)) {
+		sub := q.fork()
+		sub.Select().From(rel.Table)
+		if condition != "" {
+			sub.Where(condition, args...)
+		}
+
+		in := fmt.Sprintf("%s IN (%s)", column, placeholders(sub.Dialect, len(sub.params), len(chunk)))
+		sub.Where(in, chunk...)
+
+		dest := reflect.New(reflect.SliceOf(reflect.PointerTo(rel.Schema.Type)))
+		if err := sub.Scan(ctx, dest.Interface()); err != nil {
+			return nil, err
+		}
+		related = append(related, modelStructs(dest)...)
+	}
+	return related, nil
+}
+
+// keyChunks splits keys in chunks of at most size keys
+func keyChunks(keys []any, size int) [][]any {
+	size = max(size, 1)
+	var chunks [][]any
+	for start := 0; start < len(keys); start += size {
+		chunks = append(chunks, keys[start:min(start+size, len(keys))])
+	}
+	return chunks
+}
+
+// columnValues returns the distinct non NULL values of a column of models
+func columnValues(models []reflect.Value, column string) ([]any, error) {
+	f, ok := schemaOf(models[0].Type()).columns[column]
+	if !ok {
+		return nil, fmt.Errorf("%s has no field for column %s", models[0].Type().Name(), column)
+	}
+
+	var values []any
+	seen := make(map[string]bool)
+	for _, model := range models {
+		value := model.Field(f.Index)
+		key, ok := valueKey(value)
+		if !ok || seen[key] {
+			continue
+		}
+		seen[key] = true
+		values = append(values, reflect.Indirect(value).Interface())
+	}
+	return values, nil
+}
+
+// placeholders returns n comma separated placeholders following offset
+// bound parameters
+func placeholders(dialect Dialect, offset int, n int) string {
+	p := make([]string, n)
+	for i := range p {
+		p[i] = dialect.GetPlaceholder(offset + i + 1)
+	}
+	return strings.Join(p, ", ")
+}
+
+// linkKey returns the key of a value scanned from a join table, drivers
+// return some integer columns as []byte
+func linkKey(value interface{}) string {
+	if b, ok := value.([]byte); ok {
+		return string(b)
+	}
+	return fmt.Sprint(value)
+}
+
+// assignRelated sets the relation field of every parent to its related models
+func assignRelated(parents []reflect.Value, rel *relation, related []reflect.Value) {
+	parentSchema := schemaOf(parents[0].Type())
+
+	parentColumn, relatedColumn := rel.References, rel.ForeignKey
+	if rel.Kind == belongsTo {
+		parentColumn, relatedColumn = rel.ForeignKey, rel.References
+	}
+	parentField := parentSchema.columns[parentColumn]
+	relatedField := rel.Schema.columns[relatedColumn]
+
+	groups := make(map[string][]reflect.Value)
+	for _, r := range related {
+		if key, ok := valueKey(r.Field(relatedField.Index)); ok {
+			groups[key] = append(groups[key], r)
+		}
+	}
+
+	for _, parent := range parents {
+		var matches []reflect.Value
+		if key, ok := valueKey(parent.Field(parentField.Index)); ok {
+			matches = groups[key]
+		}
+		setRelated(parent.Field(rel.Field), matches)
+	}
+}
+
+// assignManyToMany sets the relation field of every parent to the related
+// models it is linked to
+func assignManyToMany(parents []reflect.Value, rel *relation, related []reflect.Value, links map[string][]string) {
+	parentField := schemaOf(parents[0].Type()).columns[rel.ForeignKey]
+	relatedField := rel.Schema.columns[rel.References]
+
+	byKey := make(map[string]reflect.Value)
+	for _, r := range related {
+		if key, ok := valueKey(r.Field(relatedField.Index)); ok {
+			byKey[key] = r
+		}
+	}
+
+	for _, parent := range parents {
+		var matches []reflect.Value
+		if key, ok := valueKey(parent.Field(parentField.Index)); ok {
+			for _, relatedKey := range links[key] {
+				if r, ok := byKey[relatedKey]; ok {
+					matches = append(matches, r)
+				}
+			}
+		}
+		setRelated(parent.Field(rel.Field), matches)
+	}
+}
+
+// setRelated sets a struct, pointer or slice field to the related models
+func setRelated(field reflect.Value, related []reflect.Value) {
+	switch field.Kind() {
+	case reflect.Slice:
+		isPtr := field.Type().Elem().Kind() == reflect.Pointer
+		slice := reflect.MakeSlice(field.Type(), 0, len(related))
+		for _, r := range related {
+			if isPtr {
+				slice = reflect.Append(slice, r.Addr())
+			} else {
+				slice = reflect.Append(slice, r)
+			}
+		}
+		field.Set(slice)
+	case reflect.Pointer:
+		if len(related) == 0 {
+			field.Set(reflect.Zero(field.Type()))
+		} else {
+			field.Set(related[0].Addr())
+		}
+	case reflect.Struct:
+		if len(related) == 0 {
+			field.Set(reflect.Zero(field.Type()))
+		} else {
+			field.Set(related[0])
+		}
+	}
+}
+
+// modelStructs returns the addressable structs held by a pointer to a
+// struct or a pointer to a slice of structs
+func modelStructs(v reflect.Value) []reflect.Value {
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		return []reflect.Value{v}
+	case reflect.Slice:
+		structs := make([]reflect.Value, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			elem := v.Index(i)
+			if elem.Kind() == reflect.Pointer {
+				if elem.IsNil() {
+					continue
+				}
+				elem = elem.Elem()
+			}
+			structs = append(structs, elem)
+		}
+		return structs
+	}
+	return nil
+}
+
+// valueKey returns a comparable key of a column value, ok is false for NULL
+func valueKey(v reflect.Value) (string, bool) {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return "", false
+		}
+		v = v.Elem()
+	}
+	return fmt.Sprint(v.Interface()), true
+}
@@ -4,9 +4,12 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"math"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	_ "github.com/jackc/pgx/v5/stdlib"
 )
@@ -14,12 +17,60 @@ import (
 type QueryBuilder struct {
 	query        strings.Builder
 	db           *sql.DB
+	tx           *sql.Tx
 	logger       Logger
 	Dialect      Dialect
 	params       []interface{}
 	currentTable string
+	// fields are the fields of the SELECT written between fieldsAt and
+	// fieldsEnd, aliases the aliases of the tables by name
+	fields       []selectField
+	fieldsAt     int
+	fieldsEnd    int
+	aliases      map[string]string
 	operations   []string
 	returning    []string
+	columns      []string
+	rows         [][]any
+	valuesOffset int
+	model        any
+	generated    []string
+	ctes         []cte
+	conflict     *conflictClause
+	lock         *lockClause
+	// sources are the tables an UPDATE reads from or a DELETE joins with,
+	// rendered after the table ending at tableEnd or the SET ending at setEnd
+	// which follow tableParams and setParams parameters. With ? placeholders
+	// the parameters of the sources are kept in sourceParams to be bound
+	// where the sources are rendered.
+	sources      []string
+	sourceParams []any
+	tableEnd     int
+	tableParams  int
+	setEnd       int
+	setParams    int
+	// whereAt is where the conditions of the WHERE clause start and
+	// whereParams the number of parameters before them
+	whereAt     int
+	whereParams int
+	// embedded is the number of parameters of subqueries and expressions,
+	// which the $n placeholders written in conditions do not count
+	embedded int
+	preloads []preload
+	err      error
+	// mapping is the MappingMode of the current query, defaultMapping the
+	// one of every query
+	mapping        MappingMode
+	defaultMapping MappingMode
+	quoting        QuoteMode
+}
+
+// selectField is a field of a SELECT, column names are prefixed by From
+// and the other fields but expressions qualified with the table aliases
+type selectField struct {
+	sql    string
+	column bool
+	expr   bool
 }
 
 func NewQueryBuilder(db *sql.DB, dialect Dialect, logger Logger) *QueryBuilder {
@@ -34,6 +85,15 @@ func NewQueryBuilder(db *sql.DB, dialect Dialect, logger Logger) *QueryBuilder {
 	}
 }
 
+// fork returns a new QueryBuilder sharing the connection, dialect and logger
+func (q *QueryBuilder) fork() *QueryBuilder {
+	f := NewQueryBuilder(q.db, q.Dialect, q.logger)
+	f.tx = q.tx
+	f.defaultMapping = q.defaultMapping
+	f.quoting = q.quoting
+	return f
+}
+
 func (q *QueryBuilder) Close() error {
 	if q.db != nil {
 		return q.db.Close()
@@ -41,7 +101,9 @@ func (q *QueryBuilder) Close() error {
 	return nil
 }
 
-func (q *QueryBuilder) Select(fields ...string) *QueryBuilder {
+// Select starts a SELECT of the fields, which are column names or raw SQL,
+// subqueries and expressions e.g. Select("id", goorm.As(count, "post_count"))
+func (q *QueryBuilder) Select(fields ...any) *QueryBuilder {
 	if len(fields) == 0 {
 		q.query.WriteString("SELECT *")
 		return q
@@ -49,28 +111,13 @@ func (q *QueryBuilder) Select(fields ...string) *QueryBuilder {
 
 	q.query.WriteString("SELECT ")
 	q.operations = append(q.operations, "SELECT")
-
-	formattedFields := make([]string, len(fields))
-	for i, field := range fields {
-		field = strings.TrimSpace(field) // Bug Fix 3: Trim spaces
-		if strings.Contains(field, "(") ||
-			field == "*" ||
-			strings.Contains(field, ".") ||
-			strings.Contains(field, " as ") ||
-			strings.Contains(field, " AS ") {
-			formattedFields[i] = field
-		} else {
-			formattedFields[i] = field
-		}
-	}
-
-	q.query.WriteString(strings.Join(formattedFields, ", "))
+	q.selectFields(fields)
 	return q
 }
 
 // Distinct adds the DISTINCT keyword to the query
 // e.g. SELECT DISTINCT * FROM users
-func (q *QueryBuilder) SelectDistinct(fields ...string) *QueryBuilder {
+func (q *QueryBuilder) SelectDistinct(fields ...any) *QueryBuilder {
 	if len(fields) == 0 {
 		q.query.WriteString("SELECT DISTINCT *")
 		return q
@@ -78,109 +125,197 @@ func (q *QueryBuilder) SelectDistinct(fields ...string) *QueryBuilder {
 
 	q.query.WriteString("SELECT DISTINCT ")
 	q.operations = append(q.operations, "SELECT DISTINCT")
+	q.selectFields(fields)
+	return q
+}
 
-	formattedFields := make([]string, len(fields))
-	for i, field := range fields {
-		field = strings.TrimSpace(field)
-		if strings.Contains(field, "(") ||
-			field == "*" ||
-			strings.Contains(field, ".") ||
-			strings.Contains(field, " as ") ||
-			strings.Contains(field, " AS ") {
-			formattedFields[i] = field
-		} else {
-			formattedFields[i] = field
+// selectFields writes the fields of a SELECT and keeps them for From,
+// which prefixes the column names with its table
+func (q *QueryBuilder) selectFields(fields []any) {
+	q.fields = q.fields[:0]
+	for _, field := range fields {
+		s, ok := field.(string)
+		if !ok {
+			q.fields = append(q.fields, selectField{sql: q.embed(field), expr: true})
+			continue
+		}
+		for _, f := range splitFields(s) {
+			f = strings.TrimSpace(f)
+			q.fields = append(q.fields, selectField{sql: f, column: isColumnName(f)})
 		}
 	}
 
-	q.query.WriteString(strings.Join(formattedFields, ", "))
-	return q
+	list := q.selectList("")
+	q.fieldsAt = q.query.Len()
+	q.fieldsEnd = q.fieldsAt + len(list)
+	q.query.WriteString(list)
 }
 
-func (q *QueryBuilder) From(table string) *QueryBuilder {
-	table = strings.TrimSpace(table)
-	if table == "" {
-		return q
+// selectList joins the fields of the SELECT, prefixing column names with
+// table when it is not empty
+func (q *QueryBuilder) selectList(table string) string {
+	fields := make([]string, len(q.fields))
+	for i, field := range q.fields {
+		switch {
+		case field.column && table != "":
+			fields[i] = q.quoteName(table + "." + field.sql)
+		case !field.expr:
+			fields[i] = q.quoteAliased(q.qualify(field.sql))
+		default:
+			fields[i] = field.sql
+		}
 	}
+	return strings.Join(fields, ", ")
+}
 
-	// Extract table name and alias
-	parts := strings.Fields(table)
-	q.currentTable = parts[0]
+// From sets the table of the query, a name with an optional alias
+// e.g. From("users u"), or a subquery or expression named by alias
+// e.g. From(sub, "recent"). The columns of the SELECT and of the
+// conditions are prefixed with the alias, or else the table.
+func (q *QueryBuilder) From(table any, alias ...string) *QueryBuilder {
+	if hasOperation(q.operations, "UPDATE") {
+		return q.addSource("UPDATE", table, alias...)
+	}
+
+	var source string
+	switch t := table.(type) {
+	case string:
+		source = strings.TrimSpace(t)
+		if source == "" {
+			return q
+		}
 
-	// Handle schema.table format
-	if strings.Contains(q.currentTable, ".") {
-		tableParts := strings.Split(q.currentTable, ".")
-		q.currentTable = tableParts[len(tableParts)-1]
+		// the alias is the last word e.g. "users u" or "users AS u"
+		parts := strings.Fields(source)
+		q.currentTable = tableName(parts[0])
+		if len(parts) > 1 {
+			q.setAlias(q.currentTable, parts[len(parts)-1])
+			q.currentTable = parts[len(parts)-1]
+		}
+		source = q.quoteAliased(source)
+	default:
+		source = q.embed(table)
+		q.currentTable = ""
+	}
+	if len(alias) > 0 && alias[0] != "" {
+		source += " AS " + q.quoteName(alias[0])
+		if _, ok := table.(string); ok {
+			q.setAlias(q.currentTable, alias[0])
+		}
+		q.currentTable = alias[0]
 	}
 
 	q.operations = append(q.operations, "FROM")
 
 	// Prefix columns in SELECT clause if it exists
-	if hasOperation(q.operations, "SELECT") || hasOperation(q.operations, "SELECT DISTINCT") {
-		query := q.query.String()
-		parts := strings.SplitN(query, "FROM", 2)
-		if len(parts) > 0 {
-			selectPart := parts[0]
-			selectPart = strings.TrimPrefix(selectPart, "SELECT ")
-			selectPart = strings.TrimPrefix(selectPart, "SELECT DISTINCT ")
-			fields := splitFields(selectPart)
-
-			formattedFields := make([]string, len(fields))
-			for i, field := range fields {
-				field = strings.TrimSpace(field)
-				if strings.Contains(field, "(") ||
-					field == "*" ||
-					strings.Contains(field, ".") ||
-					strings.Contains(field, " as ") ||
-					strings.Contains(field, " AS ") {
-					formattedFields[i] = field
-				} else {
-					formattedFields[i] = fmt.Sprintf("%s.%s", q.currentTable, field)
-				}
-			}
-
-			q.query.Reset()
-			q.query.WriteString("SELECT ")
-			q.query.WriteString(strings.Join(formattedFields, ", "))
-		}
+	if len(q.fields) > 0 {
+		q.renderFields()
 	}
 
 	q.query.WriteString(" FROM ")
-	q.query.WriteString(table)
+	q.query.WriteString(source)
 
 	return q
 }
 
-func (q *QueryBuilder) Where(condition string, args ...interface{}) *QueryBuilder {
-	if condition == "" {
+// Where adds a condition, raw SQL whose columns are prefixed with the table
+// or an expression e.g. Where(goorm.Case(...).End()). The first condition
+// starts the WHERE clause and the next ones are joined with AND, conditions
+// with OR are enclosed in parentheses then. Placeholders are numbered like
+// the parameters given to the builder, not counting the ones of subqueries
+// and expressions, e.g. Where("id = $1", 1), or written ? and
+// numbered by the builder e.g. Where("created_at > ?", t), or named and
+// bound from a map or struct e.g. Where("email = :email", map[string]any{"email": email}).
+func (q *QueryBuilder) Where(condition any, args ...interface{}) *QueryBuilder {
+	if condition == "" || condition == nil {
 		return q
 	}
 
-	q.params = append(q.params, args...)
-	q.operations = append(q.operations, "WHERE")
-
-	if q.currentTable != "" {
-		condition = q.prefixColumns(condition)
+	// the WHERE clause starts before the parameters of its first condition
+	first := !hasOperation(q.operations, "WHERE")
+	if first {
+		q.startWhere()
+	}
+	sql := q.bindCondition(condition, args)
+	if first {
+		q.query.WriteString(sql)
+		return q
 	}
 
-	q.query.WriteString(" WHERE ")
-	q.query.WriteString(condition)
-
+	q.groupWhere()
+	if hasTopLevelOr(sql) {
+		sql = "(" + sql + ")"
+	}
+	q.query.WriteString(" AND " + sql)
 	return q
 }
 
-func (q *QueryBuilder) And(condition string, args ...any) *QueryBuilder {
+func (q *QueryBuilder) And(condition any, args ...any) *QueryBuilder {
 	return q.handleClause("AND", condition, args...)
 }
 
-func (q *QueryBuilder) Or(condition string, args ...any) *QueryBuilder {
+func (q *QueryBuilder) Or(condition any, args ...any) *QueryBuilder {
 	return q.handleClause("OR", condition, args...)
 }
 
-func (q *QueryBuilder) Not(condition string, args ...any) *QueryBuilder {
+// Not adds a negated condition joined with AND
+func (q *QueryBuilder) Not(condition any, args ...any) *QueryBuilder {
 	return q.handleClause("NOT", condition, args...)
 }
 
+// startWhere writes the WHERE keyword and records where its conditions start
+func (q *QueryBuilder) startWhere() {
+	q.operations = append(q.operations, "WHERE")
+	q.query.WriteString(" WHERE ")
+	q.whereAt = q.query.Len()
+	q.whereParams = len(q.params)
+}
+
+// groupWhere encloses the conditions written so far in parentheses when
+// they have OR, so that an AND added by Where applies to all of them
+func (q *QueryBuilder) groupWhere() {
+	query := q.query.String()
+	if q.whereAt == 0 || q.whereAt > len(query) || !hasTopLevelOr(query[q.whereAt:]) {
+		return
+	}
+	q.query.Reset()
+	q.query.WriteString(query[:q.whereAt] + "(" + query[q.whereAt:] + ")")
+}
+
+// bindCondition adds the arguments of a condition and renders it, numbering
+// its ? or named placeholders after the parameters of the query and its $n
+// placeholders after the ones of subqueries and expressions
+func (q *QueryBuilder) bindCondition(condition any, args []any) string {
+	offset := len(q.params)
+	c, ok := condition.(string)
+	switch {
+	case ok && strings.Contains(c, ":") && isNamedArg(args):
+		sql, values, err := bindNamed(q.Dialect, c, offset, args[0])
+		if err != nil {
+			q.err = err
+		}
+		condition = sql
+		args = values
+	case ok:
+		condition = q.bindPlaceholders(c, len(args))
+	}
+	q.params = append(q.params, args...)
+	return q.condition(condition)
+}
+
+// condition renders a raw condition with its columns prefixed with the
+// table or embeds an expression
+func (q *QueryBuilder) condition(condition any) string {
+	c, ok := condition.(string)
+	if !ok {
+		return q.embed(condition)
+	}
+	if q.currentTable != "" {
+		return q.prefixColumns(c)
+	}
+	return q.qualifyCondition(c)
+}
+
 func (q *QueryBuilder) Like(column string, value string) *QueryBuilder {
 	q.query.WriteString(" LIKE " + column)
 	q.query.WriteString(" '")
@@ -197,7 +332,13 @@ func (q *QueryBuilder) NotLike(column string, value string) *QueryBuilder {
 	return q
 }
 
+// In adds IN with the values, or with a subquery given as a *QueryBuilder
+// or expression e.g. Where("id").In("id", sub)
 func (q *QueryBuilder) In(column string, values ...any) *QueryBuilder {
+	if len(values) == 1 && isQuery(values[0]) {
+		q.query.WriteString(" IN " + q.embedQuery(values[0]))
+		return q
+	}
 	q.query.WriteString(" IN (")
 	for i, value := range values {
 		if i > 0 {
@@ -209,7 +350,12 @@ func (q *QueryBuilder) In(column string, values ...any) *QueryBuilder {
 	return q
 }
 
+// NotIn adds NOT IN with the values or a subquery like In
 func (q *QueryBuilder) NotIn(column string, values ...any) *QueryBuilder {
+	if len(values) == 1 && isQuery(values[0]) {
+		q.query.WriteString(" NOT IN " + q.embedQuery(values[0]))
+		return q
+	}
 	q.query.WriteString(" NOT IN (")
 	for i, value := range values {
 		if i > 0 {
@@ -254,7 +400,10 @@ func (q *QueryBuilder) NotBetween(column string, values ...any) *QueryBuilder {
 }
 
 func (q *QueryBuilder) InsertInto(table string) *QueryBuilder {
-	q.query.WriteString("INSERT INTO " + table)
+	q.query.WriteString("INSERT INTO " + q.quoteAliased(table))
+	if parts := strings.Fields(table); len(parts) > 0 {
+		q.currentTable = parts[0]
+	}
 	return q
 }
 
@@ -264,119 +413,316 @@ func (q *QueryBuilder) Columns(values ...any) *QueryBuilder {
 		if i > 0 {
 			q.query.WriteString(", ")
 		}
-		q.query.WriteString(strings.Join(strings.Split(fmt.Sprint(value), " "), ","))
+		names := strings.Split(fmt.Sprint(value), " ")
+		quoted := make([]string, len(names))
+		for j, name := range names {
+			quoted[j] = q.quoteName(name)
+		}
+		q.query.WriteString(strings.Join(quoted, ","))
+		q.columns = append(q.columns, names...)
 	}
 	q.query.WriteString(")")
 	return q
 }
 
+// Values adds a row to insert, it can be called once per row or with all
+// the rows e.g. Values([][]any{{"John", "john@mail.com"}, {"Jane", "jane@mail.com"}}).
+// Placeholders are numbered across the rows and the insert is split into
+// several statements when the rows exceed the parameter limit of the
+// dialect, these statements only share a transaction within Transaction.
 func (q *QueryBuilder) Values(values ...any) *QueryBuilder {
-	q.query.WriteString(" VALUES (")
-	for i, value := range values {
-		if i > 0 {
-			q.query.WriteString(", ")
+	rows := [][]any{values}
+	if len(values) == 1 {
+		if r, ok := values[0].([][]any); ok {
+			rows = r
 		}
-		q.query.WriteString(q.Dialect.GetPlaceholder(i + 1))
-		q.params = append(q.params, value)
 	}
-	q.query.WriteString(")")
+
+	if len(q.rows) == 0 {
+		q.valuesOffset = len(q.params)
+	}
+	for _, row := range rows {
+		if len(q.rows) > 0 && len(row) != len(q.rows[0]) {
+			q.err = fmt.Errorf("rows to insert must have %d values, got %d", len(q.rows[0]), len(row))
+			return q
+		}
+		q.rows = append(q.rows, row)
+		q.params = append(q.params, row...)
+	}
 	return q
 }
 
+// Update starts an UPDATE of table, From adds tables to read from e.g.
+//
+//	qb.Update("users").From("staging s").SetExpr("name = s.name").Where("users.email = s.email")
+//
+// renders UPDATE users SET ... FROM staging s WHERE ... on PostgreSQL and
+// SQLite and UPDATE users JOIN staging s SET ... WHERE ... on MySQL
 func (q *QueryBuilder) Update(table string) *QueryBuilder {
-	q.query.WriteString("UPDATE " + table)
+	q.query.WriteString("UPDATE " + q.quoteAliased(table))
+	if parts := strings.Fields(table); len(parts) > 0 {
+		q.currentTable = parts[0]
+	}
+	q.operations = append(q.operations, "UPDATE")
+	q.tableEnd = q.query.Len()
+	q.tableParams = len(q.params)
 	return q
 }
 
-func (q *QueryBuilder) Set(values map[string]string) *QueryBuilder {
-	q.query.WriteString(" SET ")
-	cnt := 0
-	for field, value := range values {
-		cnt++
-		q.query.WriteString(fmt.Sprintf("%s=%s", field, q.Dialect.GetPlaceholder(cnt)))
-		if len(values) > cnt {
-			q.query.WriteString(",")
-		}
-		q.params = append(q.params, value)
+// Set assigns a value to a column of an UPDATE, calls are chained and
+// rendered in order e.g. Set("name", "John").Set("age", 30). The value is
+// bound unless it is nil, which sets NULL, or a *QueryBuilder or
+// expression, which are rendered as SQL.
+func (q *QueryBuilder) Set(column string, value any) *QueryBuilder {
+	switch v := value.(type) {
+	case nil:
+		return q.setExpr(q.setColumn(column) + " = NULL")
+	case *QueryBuilder, Expression:
+		return q.setExpr(q.setColumn(column) + " = " + q.embed(v))
+	}
+
+	q.params = append(q.params, value)
+	return q.setExpr(fmt.Sprintf("%s = %s", q.setColumn(column), q.Dialect.GetPlaceholder(len(q.params))))
+}
+
+// setColumn qualifies the column of an assignment with the table on MySQL,
+// whose UPDATE with JOIN needs it when the tables share a column name
+func (q *QueryBuilder) setColumn(column string) string {
+	if q.Dialect.GetName() == Mysql && q.currentTable != "" && !strings.Contains(column, ".") {
+		column = q.currentTable + "." + column
+	}
+	return q.quoteName(column)
+}
+
+// SetExpr adds a raw assignment to an UPDATE e.g. SetExpr("count = count + 1")
+// or SetExpr("name = $1", name) with the placeholders of the query
+func (q *QueryBuilder) SetExpr(expression string, args ...any) *QueryBuilder {
+	return q.setExpr(q.qualifyCondition(q.bindPlaceholders(expression, len(args))), args...)
+}
+
+func (q *QueryBuilder) setExpr(expression string, args ...any) *QueryBuilder {
+	if hasOperation(q.operations, "SET") {
+		q.query.WriteString(", ")
+	} else {
+		q.operations = append(q.operations, "SET")
+		q.query.WriteString(" SET ")
+	}
+	q.query.WriteString(expression)
+	q.params = append(q.params, args...)
+	q.setEnd = q.query.Len()
+	q.setParams = len(q.params)
+	return q
+}
+
+// SetMap assigns the values of the map in the order of the columns so that
+// the SQL is the same between runs
+func (q *QueryBuilder) SetMap(values map[string]any) *QueryBuilder {
+	columns := make([]string, 0, len(values))
+	for column := range values {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+
+	for _, column := range columns {
+		q.Set(column, values[column])
 	}
 	return q
 }
 
+// subquery returns the query of the builder, which is reset, with its
+// placeholders numbered after offset parameters of the outer query
+func (q *QueryBuilder) subquery(offset int) (string, []any) {
+	query, params := q.build()
+	query = shiftPlaceholders(query, offset)
+	q.Reset()
+	return query, params
+}
+
 func (q *QueryBuilder) Delete(table string) *QueryBuilder {
-	q.query.WriteString("DELETE FROM " + table)
+	q.query.WriteString("DELETE FROM " + q.quoteAliased(table))
+	if parts := strings.Fields(table); len(parts) > 0 {
+		q.currentTable = parts[0]
+		if len(parts) > 1 {
+			q.setAlias(parts[0], parts[len(parts)-1])
+		}
+	}
+	q.operations = append(q.operations, "DELETE")
+	q.tableEnd = q.query.Len()
+	q.tableParams = len(q.params)
 	return q
 }
 
 func (q *QueryBuilder) GroupBy(fields ...string) *QueryBuilder {
+	q.operations = append(q.operations, "GROUP BY")
 	q.query.WriteString(" GROUP BY ")
 	for i, field := range fields {
 		if i > 0 {
 			q.query.WriteString(", ")
 		}
-		q.query.WriteString(field)
+		q.query.WriteString(q.quoteName(field))
 	}
 	return q
 }
 
 func (q *QueryBuilder) Having(condition string) *QueryBuilder {
+	q.operations = append(q.operations, "HAVING")
 	q.query.WriteString(" HAVING " + condition)
 	return q
 }
 
-func (q *QueryBuilder) OrderBy(fields ...string) *QueryBuilder {
+// OrderBy sorts the rows by the fields, raw SQL or expressions
+// e.g. OrderBy("name", goorm.Case("status").When("urgent", 0).Else(1).End())
+func (q *QueryBuilder) OrderBy(fields ...any) *QueryBuilder {
+	q.operations = append(q.operations, "ORDER BY")
 	q.query.WriteString(" ORDER BY ")
 	for i, field := range fields {
 		if i > 0 {
 			q.query.WriteString(", ")
 		}
-		q.query.WriteString(field)
+		if s, ok := field.(string); ok {
+			q.query.WriteString(q.quoteOrder(s))
+		} else {
+			q.query.WriteString(q.embed(field))
+		}
 	}
 	return q
 }
 
 func (q *QueryBuilder) Limit(limit int) *QueryBuilder {
+	q.operations = append(q.operations, "LIMIT")
 	q.query.WriteString(" LIMIT " + strconv.Itoa(limit))
 	return q
 }
 
 func (q *QueryBuilder) Offset(offset int) *QueryBuilder {
+	q.operations = append(q.operations, "OFFSET")
 	q.query.WriteString(" OFFSET " + strconv.Itoa(offset))
 	return q
 }
 
-// SubQuery adds a sub-query to the query
+// SubQuery adds a sub-query to the query, a raw string or a *QueryBuilder
+// whose parameters are numbered after the ones of the query
 // e.g. SELECT * FROM (SELECT * FROM users WHERE id = 1) AS users
 // e.g. SELECT * FROM (SELECT * FROM users WHERE id = 1) AS users WHERE id = 2
-func (q *QueryBuilder) SubQuery(query string) *QueryBuilder {
-	q.query.WriteString("(" + query + ")")
+func (q *QueryBuilder) SubQuery(query any) *QueryBuilder {
+	if s, ok := query.(string); ok {
+		q.query.WriteString("(" + s + ")")
+		return q
+	}
+	q.query.WriteString(q.embedQuery(query))
 	return q
 }
 
-func (q *QueryBuilder) Exists(query string) *QueryBuilder {
-	q.query.WriteString(" EXISTS (" + query + ")")
-	return q
+// Exists adds an EXISTS condition on a raw string or *QueryBuilder, which
+// can reference the tables of the outer query, starting the WHERE clause
+// or joined with AND to the previous condition
+// e.g.
+//
+//	posts := goorm.NewQueryBuilder(db, dialect, nil).Select("1").From("posts p").Where("p.user_id = u.id")
+//	qb.Select("id").From("users u").Exists(posts)
+func (q *QueryBuilder) Exists(query any) *QueryBuilder {
+	return q.exists("EXISTS", query)
 }
 
-func (q *QueryBuilder) NotExists(query string) *QueryBuilder {
-	q.query.WriteString(" NOT EXISTS (" + query + ")")
+// NotExists adds a NOT EXISTS condition like Exists
+func (q *QueryBuilder) NotExists(query any) *QueryBuilder {
+	return q.exists("NOT EXISTS", query)
+}
+
+func (q *QueryBuilder) exists(operator string, query any) *QueryBuilder {
+	keyword := q.conditionKeyword()
+	if s, ok := query.(string); ok {
+		q.query.WriteString(keyword + operator + " (" + s + ")")
+		return q
+	}
+	q.query.WriteString(keyword + operator + " " + q.embedQuery(query))
 	return q
 }
 
-func (q *QueryBuilder) LeftJoin(table string, condition string) *QueryBuilder {
+// conditionKeyword returns the keyword joining a condition to the query,
+// WHERE for the first one and AND after another condition
+func (q *QueryBuilder) conditionKeyword() string {
+	if !hasOperation(q.operations, "WHERE") {
+		q.startWhere()
+		return ""
+	}
+
+	query := strings.ToUpper(strings.TrimSpace(q.query.String()))
+	for _, keyword := range []string{" WHERE", " AND", " OR", " NOT"} {
+		if strings.HasSuffix(query, keyword) {
+			return " "
+		}
+	}
+	return " AND "
+}
+
+// LeftJoin keeps the rows of the query without a match in table, which is
+// a name with an optional alias, a *TableRef or a subquery. The condition
+// is a raw string or a *JoinCondition e.g.
+//
+//	qb.Select("users.name", "p.avatar").From("users").
+//		LeftJoin(goorm.Ref("profiles").As("p"), goorm.On("users.id", "p.user_id").And("p.avatar <> $1", ""))
+func (q *QueryBuilder) LeftJoin(table any, condition any) *QueryBuilder {
 	return q.Join("LEFT", table, condition)
 }
 
-func (q *QueryBuilder) RightJoin(table string, condition string) *QueryBuilder {
+func (q *QueryBuilder) RightJoin(table any, condition any) *QueryBuilder {
 	return q.Join("RIGHT", table, condition)
 }
 
-func (q *QueryBuilder) InnerJoin(table string, condition string) *QueryBuilder {
+func (q *QueryBuilder) InnerJoin(table any, condition any) *QueryBuilder {
 	return q.Join("INNER", table, condition)
 }
 
-// Join adds a JOIN clause to the query
-func (q *QueryBuilder) Join(joinType string, table string, condition string) *QueryBuilder {
-	q.query.WriteString(fmt.Sprintf(" %s JOIN %s ON %s", joinType, table, condition))
+// Join adds a JOIN clause to the query, the parameters of the condition
+// are numbered after the ones of the query
+func (q *QueryBuilder) Join(joinType string, table any, condition any) *QueryBuilder {
+	source := q.joinSource(table)
+	q.query.WriteString(fmt.Sprintf(" %s JOIN %s", joinType, source))
+	q.query.WriteString(q.joinCondition(condition))
+	return q
+}
+
+// JoinRelation adds a JOIN clause for the relation name of model using the
+// keys of the relation, many to many relations also join the join table
+// e.g. JoinRelation("LEFT", &User{}, "Posts") adds
+// LEFT JOIN posts ON posts.user_id = users.id
+func (q *QueryBuilder) JoinRelation(joinType string, model any, name string) *QueryBuilder {
+	s := schemaOf(reflect.TypeOf(model))
+	rel, err := s.relation(name)
+	if err != nil {
+		q.err = err
+		return q
+	}
+
+	table := q.currentTable
+	if table == "" {
+		table = s.Table
+	}
+
+	switch rel.Kind {
+	case hasOne, hasMany:
+		q.Join(joinType, rel.Table, fmt.Sprintf("%s.%s = %s.%s", rel.Table, rel.ForeignKey, table, rel.References))
+	case belongsTo:
+		q.Join(joinType, rel.Table, fmt.Sprintf("%s.%s = %s.%s", rel.Table, rel.References, table, rel.ForeignKey))
+	case manyToMany:
+		q.Join(joinType, rel.JoinTable, fmt.Sprintf("%s.%s = %s.%s", rel.JoinTable, rel.JoinForeignKey, table, rel.ForeignKey))
+		q.Join(joinType, rel.Table, fmt.Sprintf("%s.%s = %s.%s", rel.Table, rel.References, rel.JoinTable, rel.JoinReferences))
+	}
+	return q
+}
+
+// CaseStart writes the CASE keyword, followed by the value of a simple
+// CASE when given, the Case expression binds its values instead. Right
+// after the fields of a SELECT, the CASE is added to them.
+func (q *QueryBuilder) CaseStart(value ...string) *QueryBuilder {
+	if len(q.fields) > 0 && q.query.Len() == q.fieldsEnd {
+		q.query.WriteString(",")
+	}
+	q.query.WriteString(" CASE")
+	if len(value) > 0 {
+		q.query.WriteString(" " + value[0])
+	}
 	return q
 }
 
@@ -430,30 +776,86 @@ func (q *QueryBuilder) RestartIdentity() *QueryBuilder {
 
 // GetSql returns the query string
 func (q *QueryBuilder) GetSql() string {
-	return q.formatQuery()
+	query, _ := q.formatQuery()
+	return query
 }
 
-func (q *QueryBuilder) formatQuery() string {
-	query := q.query.String()
+// formatQuery returns the complete query and its parameters
+func (q *QueryBuilder) formatQuery() (string, []any) {
+	query, args := q.build()
 
 	if len(q.returning) > 0 && q.Dialect != nil && supportsReturning(q.Dialect) {
-		returningFields := make([]string, len(q.returning))
-		for i, field := range q.returning {
-			if !strings.Contains(field, ".") && q.currentTable != "" {
-				returningFields[i] = fmt.Sprintf("%s.%s", q.currentTable, field)
-			} else {
-				returningFields[i] = field
-			}
-		}
-		query += " RETURNING " + strings.Join(returningFields, ", ")
+		query += " RETURNING " + q.returningList()
 	}
 
 	if !strings.HasSuffix(query, ";") {
 		query += ";"
 	}
 
-	q.logger.Info(query, "args", q.params)
+	q.logger.Info(query, "args", args)
+
+	return query, args
+}
 
+// returningList renders the returned fields qualified with the table
+func (q *QueryBuilder) returningList() string {
+	fields := make([]string, len(q.returning))
+	for i, field := range q.returning {
+		if !strings.Contains(field, ".") && q.currentTable != "" {
+			fields[i] = q.quoteName(q.currentTable + "." + field)
+		} else {
+			fields[i] = q.quoteName(field)
+		}
+	}
+	return strings.Join(fields, ", ")
+}
+
+// build returns the query without RETURNING preceded by its common table
+// expressions and the parameters of both
+func (q *QueryBuilder) build() (string, []any) {
+	if len(q.ctes) == 0 {
+		return q.body(), q.args()
+	}
+
+	prefix, args := q.withSQL()
+	query := prefix + shiftPlaceholders(q.body(), len(args))
+	return query, append(args, q.args()...)
+}
+
+// args returns a copy of the parameters, as the builder is reset and reused
+// once it is built, with the ones of the sources where they are rendered
+func (q *QueryBuilder) args() []any {
+	if len(q.sourceParams) == 0 {
+		return append([]any(nil), q.params...)
+	}
+
+	at := q.tableParams
+	if hasOperation(q.operations, "UPDATE") && q.Dialect.GetName() != Mysql {
+		at = q.setParams
+		if q.setEnd == 0 {
+			at = len(q.params)
+		}
+	}
+	args := append([]any(nil), q.params[:at]...)
+	args = append(args, q.sourceParams...)
+	return append(args, q.params[at:]...)
+}
+
+// body returns the query with its deferred clauses but without RETURNING
+func (q *QueryBuilder) body() string {
+	query := q.query.String()
+	if len(q.sources) > 0 {
+		query = q.sourcesSQL(query)
+	}
+	if len(q.rows) > 0 {
+		query += valuesSQL(q.Dialect, q.valuesOffset, q.rows)
+	}
+	if q.conflict != nil {
+		query += q.conflictSQL()
+	}
+	if q.lock != nil {
+		query += q.lockSQL()
+	}
 	return query
 }
 
@@ -463,14 +865,32 @@ func (q *QueryBuilder) Exec(ctx context.Context) (*sql.Rows, error) {
 		ctx = context.Background()
 	}
 
-	query := q.GetSql()
+	if q.err == nil {
+		q.err = q.checkCTEs()
+	}
+	if q.err == nil {
+		q.err = q.checkConflict()
+	}
+	if q.err != nil {
+		err := q.err
+		q.Reset()
+		q.logger.Error(err.Error())
+		return nil, err
+	}
+
+	if batches := q.batches(); len(batches) > 1 {
+		return q.execBatches(ctx, batches)
+	}
+	q.checkLock()
+
+	query, args := q.formatQuery()
 	defer q.Reset()
 
 	var rows *sql.Rows
 	var err error
 	if len(q.returning) > 0 {
 		if q.Dialect != nil && supportsReturning(q.Dialect) {
-			rows, err = q.db.QueryContext(ctx, query, q.params...)
+			rows, err = q.conn().QueryContext(ctx, query, args...)
 		} else {
 			// Fallback for databases that don't support RETURNING
 			// This might involve doing the insert/update first
@@ -478,7 +898,7 @@ func (q *QueryBuilder) Exec(ctx context.Context) (*sql.Rows, error) {
 			rows, err = q.handleReturningFallback(ctx)
 		}
 	} else {
-		rows, err = q.db.QueryContext(ctx, query, q.params...)
+		rows, err = q.conn().QueryContext(ctx, query, args...)
 	}
 
 	if err != nil {
@@ -491,64 +911,104 @@ func (q *QueryBuilder) Exec(ctx context.Context) (*sql.Rows, error) {
 
 // Scan maps struct fields to db field
 func (q *QueryBuilder) Scan(ctx context.Context, model interface{}) error {
+	preloads := q.preloads
+	mode := q.mappingMode()
 	rows, err := q.Exec(ctx)
 	if err != nil {
 		return err
 	}
-	return q.mapToModel(rows, model)
+	if err := q.mapToModel(rows, model, mode); err != nil {
+		return err
+	}
+	return q.preload(ctx, model, preloads)
 }
 
 func (q *QueryBuilder) exec(ctx context.Context, model interface{}) error {
+	mode := q.mappingMode()
+	if batches := q.batches(); len(batches) > 1 && q.err == nil {
+		return q.scanBatches(ctx, batches, model, mode)
+	}
 	rows, err := q.Exec(ctx)
 	if err != nil {
 		return err
 	}
-	return q.mapToModel(rows, model)
+	return q.mapToModel(rows, model, mode)
 }
 
 func (q *QueryBuilder) Reset() {
 	q.query.Reset()
 	q.operations = make([]string, 0)
 	q.returning = make([]string, 0)
+	q.columns = nil
+	q.rows = nil
+	q.valuesOffset = 0
+	q.model = nil
+	q.generated = nil
+	q.ctes = nil
+	q.conflict = nil
+	q.lock = nil
+	q.sources = nil
+	q.sourceParams = nil
+	q.tableEnd = 0
+	q.tableParams = 0
+	q.setEnd = 0
+	q.setParams = 0
+	q.whereAt = 0
+	q.whereParams = 0
+	q.embedded = 0
 	q.params = make([]interface{}, 0)
+	q.preloads = nil
+	q.err = nil
+	q.mapping = 0
 	q.currentTable = ""
+	q.fields = nil
+	q.fieldsAt = 0
+	q.fieldsEnd = 0
+	q.aliases = nil
 }
 
-func (q *QueryBuilder) handleClause(clause string, condition string, args ...interface{}) *QueryBuilder {
-	if condition == "" {
+func (q *QueryBuilder) handleClause(clause string, condition any, args ...interface{}) *QueryBuilder {
+	if condition == "" || condition == nil {
 		return q
 	}
 
-	if q.currentTable != "" {
-		condition = q.prefixColumns(condition)
+	// the first condition starts the WHERE clause, before its parameters
+	first := !hasOperation(q.operations, "WHERE")
+	if first {
+		q.startWhere()
+	}
+	sql := q.bindCondition(condition, args)
+	if first {
+		if clause == "NOT" {
+			sql = "NOT " + sql
+		}
+		q.query.WriteString(sql)
+		return q
 	}
 
+	if clause == "NOT" {
+		clause = "AND NOT"
+	}
 	q.query.WriteString(fmt.Sprintf(" %s ", clause))
-	q.query.WriteString(condition)
-	q.params = append(q.params, args...)
+	q.query.WriteString(sql)
 
 	return q
 }
 
 func (q *QueryBuilder) handleReturningFallback(ctx context.Context) (*sql.Rows, error) {
-	// Start a transaction
-	tx, err := q.db.BeginTx(ctx, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to begin transaction: %w", err)
-	}
+	// The rows of the SELECT are returned to the caller, so the statements
+	// cannot run in a transaction committed here as committing closes them.
+	// Within Transaction both run in its transaction.
+	conn := q.conn()
 
 	// Execute the original query without RETURNING
-	originalQuery := q.query.String()
+	originalQuery, args := q.build()
 	if !strings.HasSuffix(originalQuery, ";") {
 		originalQuery += ";"
 	}
 
-	result, err := tx.ExecContext(ctx, originalQuery, q.params...)
+	result, err := conn.ExecContext(ctx, originalQuery, args...)
 	if err != nil {
-		err := tx.Rollback()
-		if err != nil {
-			return nil, fmt.Errorf("failed to rollback transaction: %w", err)
-		}
 		return nil, fmt.Errorf("failed to execute query: %w", err)
 	}
 
@@ -556,36 +1016,35 @@ func (q *QueryBuilder) handleReturningFallback(ctx context.Context) (*sql.Rows,
 	if strings.HasPrefix(strings.ToUpper(strings.TrimSpace(originalQuery)), "INSERT") {
 		lastID, err := result.LastInsertId()
 		if err != nil {
-			err := tx.Rollback()
-			if err != nil {
-				return nil, fmt.Errorf("failed to rollback transaction: %w", err)
-			}
 			return nil, fmt.Errorf("failed to get last insert ID: %w", err)
 		}
 
+		// MySQL returns the id of the first row of a multi-row insert and
+		// SQLite the one of the last row
+		if _, ok := q.Dialect.(*SQLite); ok && len(q.rows) > 1 {
+			lastID -= int64(len(q.rows) - 1)
+		}
+
 		// Build SELECT query to fetch the returned fields
+		id := q.quoteName("id") // Assuming 'id' is the primary key
 		var selectQuery strings.Builder
 		selectQuery.WriteString("SELECT ")
-		selectQuery.WriteString(strings.Join(q.returning, ", "))
+		selectQuery.WriteString(q.returningList())
 		selectQuery.WriteString(" FROM ")
-		selectQuery.WriteString(q.currentTable)
-		selectQuery.WriteString(" WHERE id = ?") // Assuming 'id' is the primary key
+		selectQuery.WriteString(q.quoteName(q.currentTable))
+		if len(q.rows) > 1 {
+			// the ids of the rows of a single insert follow the first one
+			selectQuery.WriteString(" WHERE " + id + " >= ? ORDER BY " + id + " LIMIT " + strconv.Itoa(len(q.rows)))
+		} else {
+			selectQuery.WriteString(" WHERE " + id + " = ?")
+		}
 
 		// Execute SELECT query
-		rows, err := tx.QueryContext(ctx, selectQuery.String(), lastID)
+		rows, err := conn.QueryContext(ctx, selectQuery.String(), lastID)
 		if err != nil {
-			err := tx.Rollback()
-			if err != nil {
-				return nil, fmt.Errorf("failed to rollback transaction: %w", err)
-			}
 			return nil, fmt.Errorf("failed to fetch returned fields: %w", err)
 		}
 
-		// Commit transaction
-		if err := tx.Commit(); err != nil {
-			return nil, fmt.Errorf("failed to commit transaction: %w", err)
-		}
-
 		return rows, nil
 	}
 
@@ -593,71 +1052,68 @@ func (q *QueryBuilder) handleReturningFallback(ctx context.Context) (*sql.Rows,
 	if strings.HasPrefix(strings.ToUpper(strings.TrimSpace(originalQuery)), "UPDATE") ||
 		strings.HasPrefix(strings.ToUpper(strings.TrimSpace(originalQuery)), "DELETE") {
 
-		// Get the WHERE clause from the original query
-		whereParts := strings.Split(originalQuery, "WHERE")
-		if len(whereParts) != 2 {
-			err := tx.Rollback()
-			if err != nil {
-				return nil, fmt.Errorf("failed to rollback transaction: %w", err)
-			}
+		// the conditions of the WHERE clause select the affected rows
+		if !hasOperation(q.operations, "WHERE") {
 			return nil, fmt.Errorf("cannot handle RETURNING clause without WHERE condition")
 		}
 
 		// Build SELECT query
 		var selectQuery strings.Builder
 		selectQuery.WriteString("SELECT ")
-		selectQuery.WriteString(strings.Join(q.returning, ", "))
+		selectQuery.WriteString(q.returningList())
 		selectQuery.WriteString(" FROM ")
-		selectQuery.WriteString(q.currentTable)
+		selectQuery.WriteString(q.quoteName(q.currentTable))
+		if len(q.sources) > 0 {
+			selectQuery.WriteString(" JOIN " + strings.Join(q.sources, " JOIN "))
+		}
 		selectQuery.WriteString(" WHERE ")
-		selectQuery.WriteString(strings.TrimSuffix(whereParts[1], ";"))
+		selectQuery.WriteString(q.query.String()[q.whereAt:])
 
 		// Execute SELECT query
-		rows, err := tx.QueryContext(ctx, selectQuery.String(), q.params...)
+		args := append(append([]any(nil), q.sourceParams...), q.params[q.whereParams:]...)
+		rows, err := conn.QueryContext(ctx, selectQuery.String(), args...)
 		if err != nil {
-			err := tx.Rollback()
-			if err != nil {
-				return nil, fmt.Errorf("failed to rollback transaction: %w", err)
-			}
 			return nil, fmt.Errorf("failed to fetch returned fields: %w", err)
 		}
 
-		if err := tx.Commit(); err != nil {
-			return nil, fmt.Errorf("failed to commit transaction: %w", err)
-		}
-
 		return rows, nil
 	}
 
-	err = tx.Rollback()
-	if err != nil {
-		return nil, fmt.Errorf("failed to rollback transaction: %w", err)
-	}
 	return nil, fmt.Errorf("unsupported query type for RETURNING fallback")
 }
 
 func (q *QueryBuilder) prefixColumns(condition string) string {
 	parts := strings.Fields(condition)
 	for i, part := range parts {
+		if strings.Contains(part, ".") {
+			parts[i] = q.quoteName(q.qualify(part))
+			continue
+		}
 		if isColumnNameInWhere(parts, i) &&
 			!strings.Contains(part, ".") &&
 			!strings.HasPrefix(part, "$") &&
 			!strings.HasPrefix(part, "'") &&
 			!strings.HasPrefix(part, "\"") &&
 			!strings.Contains(part, "(") {
-			parts[i] = fmt.Sprintf("%s.%s", q.currentTable, part)
+			parts[i] = q.quoteName(q.currentTable + "." + part)
 		}
 	}
 	return strings.Join(parts, " ")
 }
 
-func (q *QueryBuilder) mapToModel(rows *sql.Rows, model interface{}) error {
+func (q *QueryBuilder) mapToModel(rows *sql.Rows, model interface{}, mode MappingMode) error {
+	defer rows.Close()
+
 	modelValue := reflect.ValueOf(model)
 	if modelValue.Kind() != reflect.Pointer {
 		return fmt.Errorf("model must be a pointer")
 	}
 	modelValue = modelValue.Elem()
 
+	if ok, err := mapToValues(rows, modelValue); ok {
+		return err
+	}
+
 	isSlice := modelValue.Kind() == reflect.Slice
 	var elemType reflect.Type
 	if isSlice {
@@ -692,6 +1148,24 @@ func (q *QueryBuilder) mapToModel(rows *sql.Rows, model interface{}) error {
 		results = reflect.MakeSlice(modelValue.Type(), 0, 0)
 	}
 
+	// Joined rows of has many relations are grouped by the primary key
+	// of the model so that each model is returned once with its children
+	modelSchema := schemaOf(elemType)
+	grouped := len(modelSchema.hasManyFields()) > 0
+	claimed := claimedColumns(nil, modelSchema)
+	parents := make(map[string]int)
+	children := make(map[string]int)
+	currentKey := ""
+	mapped := false
+	rowIndex := 0
+
+	// the columns and fields of the first row are checked unless mapping
+	// problems are ignored
+	var tracker *mappingTracker
+	if mode == MappingLenient || mode == MappingStrict {
+		tracker = newMappingTracker()
+	}
+
 	for rows.Next() {
 		if err := rows.Scan(values...); err != nil {
 			return err
@@ -704,113 +1178,305 @@ func (q *QueryBuilder) mapToModel(rows *sql.Rows, model interface{}) error {
 		}
 
 		newStruct := reflect.New(elemType).Elem()
+		if err := fillStruct(newStruct, valueMap, selectedColumns, tracker); err != nil {
+			return err
+		}
 
-		for i := 0; i < elemType.NumField(); i++ {
-			field := elemType.Field(i)
-			fieldValue := newStruct.Field(i)
+		if grouped {
+			rowIndex++
+			key, hasKey := modelSchema.primaryKeyOf(newStruct)
+			if !hasKey && mapped && !isSlice {
+				// without a key the rows of the model cannot be grouped, a
+				// single model keeps the first one
+				break
+			}
+			if hasKey {
+				var existing reflect.Value
+				if idx, ok := parents[key]; ok && isSlice {
+					existing = reflect.Indirect(results.Index(idx))
+				} else if mapped && !isSlice {
+					if key != currentKey {
+						break
+					}
+					existing = modelValue
+				}
 
-			if !fieldValue.CanSet() {
-				continue
+				if existing.IsValid() {
+					if err := appendHasMany(existing, key, valueMap, children, claimed, nil); err != nil {
+						return err
+					}
+					continue
+				}
+			} else {
+				key = fmt.Sprintf("#%d", rowIndex)
 			}
 
-			dbTag := field.Tag.Get("db")
-			if dbTag == "" {
-				continue
+			if err := appendHasMany(newStruct, key, valueMap, children, claimed, tracker); err != nil {
+				return err
+			}
+			if isSlice {
+				parents[key] = results.Len()
 			}
+			currentKey = key
+		}
 
-			fieldType := field.Type
-			isPtr := fieldType.Kind() == reflect.Pointer
-			if isPtr {
-				fieldType = fieldType.Elem()
+		if tracker != nil {
+			if err := q.reportMapping(elemType, columns, tracker, mode); err != nil {
+				return err
 			}
+			tracker = nil
+		}
 
-			if fieldType.Kind() != reflect.Struct {
-				if value, exists := valueMap[dbTag]; exists {
-					if err := setFieldValue(fieldValue, value); err != nil {
-						return err
-					}
-				}
+		if isSlice {
+			if modelValue.Type().Elem().Kind() == reflect.Ptr {
+				ptrValue := reflect.New(elemType)
+				ptrValue.Elem().Set(newStruct)
+				results = reflect.Append(results, ptrValue)
+			} else {
+				results = reflect.Append(results, newStruct)
+			}
+		} else {
+			modelValue.Set(newStruct)
+			mapped = true
+			if !grouped {
+				break
+			}
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if isSlice {
+		modelValue.Set(results)
+	}
+
+	return nil
+}
+
+// fillStruct sets the fields of a struct, including nested structs,
+// from the columns of a single row
+func fillStruct(newStruct reflect.Value, valueMap map[string]interface{}, selectedColumns map[string]bool, tracker *mappingTracker) error {
+	elemType := newStruct.Type()
+	for i := 0; i < elemType.NumField(); i++ {
+		field := elemType.Field(i)
+		fieldValue := newStruct.Field(i)
+
+		if !fieldValue.CanSet() {
+			continue
+		}
+
+		dbTag := field.Tag.Get("db")
+		if dbTag == "" || isStructSlice(field.Type) {
+			// has many relations are mapped by appendHasMany
+			continue
+		}
+
+		fieldType := field.Type
+		isPtr := fieldType.Kind() == reflect.Pointer
+		if isPtr {
+			fieldType = fieldType.Elem()
+		}
 
+		if fieldType.Kind() != reflect.Struct || isScalarType(fieldType) {
+			if value, exists := valueMap[dbTag]; exists {
+				tracker.use(dbTag)
+				if err := setFieldValue(fieldValue, value); err != nil {
+					return fmt.Errorf("column %s: %w", dbTag, err)
+				}
 			} else {
-				hasSelectedFields := false
-				for j := 0; j < fieldType.NumField(); j++ {
-					nestedTag := fieldType.Field(j).Tag.Get("db")
-					if selectedColumns[nestedTag] {
+				tracker.miss(elemType.Name() + "." + field.Name)
+			}
 
+		} else {
+			hasSelectedFields := false
+			for j := 0; j < fieldType.NumField(); j++ {
+				nestedTag := fieldType.Field(j).Tag.Get("db")
+				if nestedTag == "" {
+					continue
+				}
+				for _, name := range nestedColumnNames(dbTag, nestedTag) {
+					if selectedColumns[name] {
 						hasSelectedFields = true
 					}
 				}
+			}
 
-				if hasSelectedFields {
-					nestedStruct := reflect.New(fieldType)
-
-					for j := 0; j < fieldType.NumField(); j++ {
-						nestedField := fieldType.Field(j)
-						nestedFieldValue := nestedStruct.Elem().Field(j)
-
-						if !nestedFieldValue.CanSet() {
-							continue
-						}
+			if hasSelectedFields {
+				nestedStruct := reflect.New(fieldType)
 
-						nestedTag := nestedField.Tag.Get("db")
-						if nestedTag == "" {
-							continue
-						}
+				for j := 0; j < fieldType.NumField(); j++ {
+					nestedField := fieldType.Field(j)
+					nestedFieldValue := nestedStruct.Elem().Field(j)
 
-						possibleNames := []string{
-							dbTag + "_" + nestedTag,
-							strings.TrimSuffix(dbTag, "s") + "_" + nestedTag,
-						}
+					if !nestedFieldValue.CanSet() {
+						continue
+					}
 
-						if nestedTag != "id" {
-							possibleNames = append(possibleNames, nestedTag)
-						}
+					nestedTag := nestedField.Tag.Get("db")
+					if nestedTag == "" {
+						continue
+					}
 
-						for _, name := range possibleNames {
-							if value, exists := valueMap[name]; exists {
-								if err := setFieldValue(nestedFieldValue, value); err != nil {
-									return err
-								}
-								break
+					filled := false
+					for _, name := range nestedColumnNames(dbTag, nestedTag) {
+						if value, exists := valueMap[name]; exists {
+							tracker.use(name)
+							if err := setFieldValue(nestedFieldValue, value); err != nil {
+								return fmt.Errorf("column %s: %w", name, err)
 							}
+							filled = true
+							break
 						}
 					}
-
-					if isPtr {
-						fieldValue.Set(nestedStruct)
-					} else {
-						fieldValue.Set(nestedStruct.Elem())
+					if !filled {
+						tracker.miss(elemType.Name() + "." + field.Name + "." + nestedField.Name)
 					}
-				} else if isPtr {
-					fieldValue.Set(reflect.Zero(field.Type))
 				}
 
+				if isPtr {
+					fieldValue.Set(nestedStruct)
+				} else {
+					fieldValue.Set(nestedStruct.Elem())
+				}
+			} else if isPtr {
+				fieldValue.Set(reflect.Zero(field.Type))
 			}
+
 		}
+	}
+	return nil
+}
 
-		if isSlice {
-			if modelValue.Type().Elem().Kind() == reflect.Ptr {
+// nestedColumnNames returns the columns a field of a nested struct is
+// looked up as e.g. profiles_avatar, profile_avatar or avatar
+func nestedColumnNames(dbTag string, nestedTag string) []string {
+	possibleNames := []string{
+		dbTag + "_" + nestedTag,
+		strings.TrimSuffix(dbTag, "s") + "_" + nestedTag,
+	}
+
+	if nestedTag != "id" {
+		possibleNames = append(possibleNames, nestedTag)
+	}
+	return possibleNames
+}
+
+// appendHasMany maps the has many relations of parent from a joined row and
+// appends them to the slice fields of parent. Children already appended for
+// the same parent are looked up in seen by their primary key, so rows of
+// deeper relations are merged into them instead of duplicating them.
+func appendHasMany(parent reflect.Value, path string, valueMap map[string]interface{}, seen map[string]int, claimed map[string]bool, tracker *mappingTracker) error {
+	for _, f := range schemaOf(parent.Type()).hasManyFields() {
+		elemType := f.Type.Elem()
+		isPtr := elemType.Kind() == reflect.Pointer
+		if isPtr {
+			elemType = elemType.Elem()
+		}
+		childSchema := schemaOf(elemType)
+
+		child := reflect.New(elemType).Elem()
+		matched, err := fillRelated(child, childSchema, f.Column, valueMap, claimed, tracker)
+		if err != nil {
+			return err
+		}
+		if !matched {
+			// LEFT JOIN without a matching row
+			continue
+		}
+
+		key, ok := childSchema.primaryKeyOf(child)
+		if !ok {
+			key = fmt.Sprint(child.Interface())
+		}
+		childPath := path + "/" + f.Name + "/" + key
+
+		slice := parent.Field(f.Index)
+		idx, exists := seen[childPath]
+		if !exists {
+			if isPtr {
 				ptrValue := reflect.New(elemType)
-				ptrValue.Elem().Set(newStruct)
-				results = reflect.Append(results, ptrValue)
+				ptrValue.Elem().Set(child)
+				slice.Set(reflect.Append(slice, ptrValue))
 			} else {
-				results = reflect.Append(results, newStruct)
+				slice.Set(reflect.Append(slice, child))
 			}
-		} else {
-			modelValue.Set(newStruct)
-			break
+			idx = slice.Len() - 1
+			seen[childPath] = idx
+		}
+
+		target := reflect.Indirect(slice.Index(idx))
+		if err := appendHasMany(target, childPath, valueMap, seen, claimedColumns(claimed, childSchema), tracker); err != nil {
+			return err
 		}
 	}
+	return nil
+}
 
-	if err := rows.Err(); err != nil {
-		return err
+// fillRelated sets the fields of a related struct from the columns of a
+// joined row. Columns are looked up as posts_title, post_title or title,
+// the bare column name is only used when no parent claims it. matched is
+// false when the row holds no prefixed primary key for the relation e.g.
+// posts_id, so columns of other joined tables do not create children.
+func fillRelated(child reflect.Value, childSchema *schema, name string, valueMap map[string]interface{}, claimed map[string]bool, tracker *mappingTracker) (bool, error) {
+	matched := false
+	found := false
+	var missing []string
+	for _, f := range childSchema.Fields {
+		if isStructSlice(f.Type) {
+			continue
+		}
+
+		prefixed := []string{
+			name + "_" + f.Column,
+			singular(name) + "_" + f.Column,
+		}
+		possibleNames := prefixed
+		if f.Column != "id" && !claimed[f.Column] {
+			possibleNames = append(possibleNames, f.Column)
+		}
+
+		filled := false
+		for i, possibleName := range possibleNames {
+			if value, exists := valueMap[possibleName]; exists {
+				tracker.use(possibleName)
+				isKey := childSchema.PrimaryKey == nil || childSchema.PrimaryKey == f
+				if value != nil && isKey && i < len(prefixed) {
+					matched = true
+				}
+				if err := setFieldValue(child.Field(f.Index), value); err != nil {
+					return false, fmt.Errorf("column %s: %w", possibleName, err)
+				}
+				filled = true
+				break
+			}
+		}
+		if !filled {
+			missing = append(missing, childSchema.Type.Name()+"."+f.Name)
+		}
+		found = found || filled
 	}
 
-	if isSlice {
-		modelValue.Set(results)
+	// fields of relations missing from the query are not reported
+	if found {
+		for _, path := range missing {
+			tracker.miss(path)
+		}
 	}
+	return matched, nil
+}
 
-	return nil
+// claimedColumns returns the columns mapped by a model and its parents
+func claimedColumns(parent map[string]bool, s *schema) map[string]bool {
+	claimed := make(map[string]bool, len(parent)+len(s.Fields))
+	for column := range parent {
+		claimed[column] = true
+	}
+	for _, f := range s.Fields {
+		claimed[f.Column] = true
+	}
+	return claimed
 }
 
 func setFieldValue(field reflect.Value, value interface{}) error {
@@ -818,51 +1484,186 @@ func setFieldValue(field reflect.Value, value interface{}) error {
 		return nil
 	}
 
+	if scanner, ok := field.Addr().Interface().(sql.Scanner); ok {
+		return scanner.Scan(value)
+	}
+
 	switch field.Kind() {
-	case reflect.Int64:
+	case reflect.Pointer:
+		ptr := reflect.New(field.Type().Elem())
+		if err := setFieldValue(ptr.Elem(), value); err != nil {
+			return err
+		}
+		field.Set(ptr)
+	case reflect.Interface:
+		if v, ok := value.([]uint8); ok {
+			value = string(v)
+		}
+		field.Set(reflect.ValueOf(value))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var n int64
 		switch v := value.(type) {
 		case int64:
-			field.SetInt(v)
+			n = v
 		case int32:
-			field.SetInt(int64(v))
+			n = int64(v)
 		case int:
-			field.SetInt(int64(v))
-		case []uint8:
-			intVal, err := strconv.ParseInt(string(v), 10, 64)
+			n = int64(v)
+		case uint64:
+			if v > math.MaxInt64 {
+				return &ConversionError{Value: value, Type: field.Type(), Reason: "overflows"}
+			}
+			n = int64(v)
+		case float64:
+			if v != math.Trunc(v) || v < math.MinInt64 || v >= math.MaxInt64 {
+				return &ConversionError{Value: value, Type: field.Type(), Reason: "is not a whole number in range"}
+			}
+			n = int64(v)
+		case []uint8, string:
+			intVal, err := strconv.ParseInt(fmt.Sprintf("%s", v), 10, 64)
 			if err != nil {
-				return err
+				return &ConversionError{Value: value, Type: field.Type(), Err: err}
 			}
-			field.SetInt(intVal)
+			n = intVal
+		default:
+			return convertValue(field, value)
 		}
+		if field.OverflowInt(n) {
+			return &ConversionError{Value: value, Type: field.Type(), Reason: "overflows"}
+		}
+		field.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var n uint64
+		switch v := value.(type) {
+		case int64, int32, int:
+			i := reflect.ValueOf(v).Int()
+			if i < 0 {
+				return &ConversionError{Value: value, Type: field.Type(), Reason: "is negative"}
+			}
+			n = uint64(i)
+		case uint64:
+			n = v
+		case float64:
+			if v != math.Trunc(v) || v < 0 || v >= math.MaxUint64 {
+				return &ConversionError{Value: value, Type: field.Type(), Reason: "is not a whole number in range"}
+			}
+			n = uint64(v)
+		case []uint8, string:
+			uintVal, err := strconv.ParseUint(fmt.Sprintf("%s", v), 10, 64)
+			if err != nil {
+				return &ConversionError{Value: value, Type: field.Type(), Err: err}
+			}
+			n = uintVal
+		default:
+			return convertValue(field, value)
+		}
+		if field.OverflowUint(n) {
+			return &ConversionError{Value: value, Type: field.Type(), Reason: "overflows"}
+		}
+		field.SetUint(n)
 	case reflect.String:
 		switch v := value.(type) {
 		case string:
 			field.SetString(v)
 		case []uint8:
 			field.SetString(string(v))
+		case time.Time:
+			field.SetString(v.Format(time.RFC3339Nano))
+		default:
+			field.SetString(fmt.Sprint(v))
 		}
 	case reflect.Bool:
 		switch v := value.(type) {
 		case bool:
 			field.SetBool(v)
-		case []uint8:
-			boolVal, err := strconv.ParseBool(string(v))
+		case int64:
+			field.SetBool(v != 0)
+		case []uint8, string:
+			boolVal, err := strconv.ParseBool(fmt.Sprintf("%s", v))
 			if err != nil {
-				return err
+				return &ConversionError{Value: value, Type: field.Type(), Err: err}
 			}
 			field.SetBool(boolVal)
+		default:
+			return convertValue(field, value)
 		}
-	case reflect.Float64:
+	case reflect.Float32, reflect.Float64:
+		var f float64
 		switch v := value.(type) {
 		case float64:
-			field.SetFloat(v)
+			f = v
+		case float32:
+			f = float64(v)
+		case int64:
+			f = float64(v)
+		case []uint8, string:
+			floatVal, err := strconv.ParseFloat(fmt.Sprintf("%s", v), 64)
+			if err != nil {
+				return &ConversionError{Value: value, Type: field.Type(), Err: err}
+			}
+			f = floatVal
+		default:
+			return convertValue(field, value)
+		}
+		if field.OverflowFloat(f) {
+			return &ConversionError{Value: value, Type: field.Type(), Reason: "overflows"}
+		}
+		field.SetFloat(f)
+	case reflect.Slice:
+		if field.Type().Elem().Kind() != reflect.Uint8 {
+			return convertValue(field, value)
+		}
+		switch v := value.(type) {
 		case []uint8:
-			floatVal, err := strconv.ParseFloat(string(v), 64)
+			field.SetBytes(append([]byte(nil), v...))
+		case string:
+			field.SetBytes([]byte(v))
+		default:
+			return convertValue(field, value)
+		}
+	case reflect.Struct:
+		if field.Type() != timeType {
+			return convertValue(field, value)
+		}
+		switch v := value.(type) {
+		case time.Time:
+			field.Set(reflect.ValueOf(v))
+		case []uint8, string:
+			t, err := parseTime(fmt.Sprintf("%s", v))
 			if err != nil {
-				return err
+				return &ConversionError{Value: value, Type: field.Type(), Err: err}
 			}
-			field.SetFloat(floatVal)
+			field.Set(reflect.ValueOf(t))
+		default:
+			return convertValue(field, value)
 		}
+	default:
+		return convertValue(field, value)
+	}
+	return nil
+}
+
+// convertValue sets the field to a value of a type setFieldValue does not
+// convert, which must be assignable to the field
+func convertValue(field reflect.Value, value any) error {
+	v := reflect.ValueOf(value)
+	if !v.Type().AssignableTo(field.Type()) {
+		return &ConversionError{Value: value, Type: field.Type(), Reason: "has an unsupported type"}
 	}
+	field.Set(v)
 	return nil
 }
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// parseTime parses the text representation of a timestamp column, drivers
+// such as MySQL without parseTime return them as []byte
+func parseTime(value string) (time.Time, error) {
+	layouts := []string{time.RFC3339Nano, "2006-01-02 15:04:05.999999999", "2006-01-02"}
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("cannot parse %q as time", value)
+}
@@ -0,0 +1,88 @@
+package goorm
+
+import (
+	"regexp"
+	"strings"
+)
+
+// QuoteMode controls how the builder quotes the table and column names it
+// is given, so that names like user, order or group can be used
+type QuoteMode int
+
+const (
+	// QuoteIdentifiers quotes names with Dialect.Quote, folding them to
+	// lower case on PostgreSQL as it does with unquoted names
+	QuoteIdentifiers QuoteMode = iota + 1
+	// QuotePreserveCase quotes names as written, for case-sensitive
+	// PostgreSQL names such as "createdAt"
+	QuotePreserveCase
+	// QuoteNone leaves names as written
+	QuoteNone
+)
+
+// SetQuoteMode sets how the names of every query are quoted,
+// QuoteIdentifiers by default
+func (q *QueryBuilder) SetQuoteMode(mode QuoteMode) *QueryBuilder {
+	q.quoting = mode
+	return q
+}
+
+var plainIdentifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_$]*$`)
+
+// quoteName quotes every part of a name which can be qualified e.g.
+// public.users.id, anything else such as a function call, a literal or a
+// placeholder is returned as is
+func (q *QueryBuilder) quoteName(name string) string {
+	if q.quoting == QuoteNone || q.Dialect == nil || name == "" {
+		return name
+	}
+
+	parts := strings.Split(name, ".")
+	for i, part := range parts {
+		switch {
+		case part == "*" && i > 0 && i == len(parts)-1:
+		case strings.HasPrefix(part, "\"") || strings.HasPrefix(part, "`"):
+		case plainIdentifier.MatchString(part):
+			if q.quoting != QuotePreserveCase && q.Dialect.GetName() == Postgres {
+				part = strings.ToLower(part)
+			}
+			parts[i] = q.Dialect.Quote(part)
+		default:
+			return name
+		}
+	}
+	return strings.Join(parts, ".")
+}
+
+// quoteAliased quotes a name followed by an alias e.g. users u or
+// users.name AS author, or returns it as is when it is something else
+func (q *QueryBuilder) quoteAliased(name string) string {
+	parts := strings.Fields(name)
+	switch {
+	case len(parts) == 1:
+		return q.quoteName(parts[0])
+	case len(parts) == 2:
+		return q.quoteName(parts[0]) + " " + q.quoteName(parts[1])
+	case len(parts) == 3 && strings.EqualFold(parts[1], "AS"):
+		return q.quoteName(parts[0]) + " AS " + q.quoteName(parts[2])
+	default:
+		return name
+	}
+}
+
+// quoteOrder quotes the column of an ORDER BY field e.g. name DESC
+func (q *QueryBuilder) quoteOrder(field string) string {
+	parts := strings.Fields(field)
+	if len(parts) == 0 {
+		return field
+	}
+	for _, part := range parts[1:] {
+		switch strings.ToUpper(part) {
+		case "ASC", "DESC", "NULLS", "FIRST", "LAST":
+		default:
+			return field
+		}
+	}
+	parts[0] = q.quoteName(parts[0])
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,308 @@
+package goorm
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+type relationKind int
+
+const (
+	hasOne relationKind = iota
+	hasMany
+	belongsTo
+	manyToMany
+)
+
+func (k relationKind) String() string {
+	switch k {
+	case hasOne:
+		return "has one"
+	case hasMany:
+		return "has many"
+	case belongsTo:
+		return "belongs to"
+	case manyToMany:
+		return "many to many"
+	}
+	return "unknown"
+}
+
+// relation describes how a struct field relates a model to another table.
+// Relations follow naming conventions and can be declared with tags e.g.
+//
+//	Profile *Profile `goorm:"foreignKey:UserID;references:ID"`
+//	Roles   []Role   `goorm:"many2many:user_roles"`
+type relation struct {
+	Name  string
+	Kind  relationKind
+	Field int
+	Table string
+	// ForeignKey is the column holding the reference, it lives on the related
+	// table for has one and has many relations and on the model for belongs
+	// to. For many to many it is the column of the model the join table links.
+	ForeignKey string
+	// References is the column referenced by ForeignKey. For many to many it
+	// is the column of the related model the join table links.
+	References string
+	// JoinTable links the models of a many to many relation, JoinForeignKey
+	// references the model and JoinReferences the related model
+	JoinTable      string
+	JoinForeignKey string
+	JoinReferences string
+	OnDelete       string
+	OnUpdate       string
+	Schema         *schema
+}
+
+// relation returns the cached relation held by the field name
+func (s *schema) relation(name string) (*relation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if rel, ok := s.relations[name]; ok {
+		return rel, nil
+	}
+
+	rel, err := resolveRelation(s.Type, name)
+	if err != nil {
+		return nil, err
+	}
+	s.relations[name] = rel
+	return rel, nil
+}
+
+// relationGraph returns every relation declared by the model
+func (s *schema) relationGraph() ([]*relation, error) {
+	var relations []*relation
+	for i := 0; i < s.Type.NumField(); i++ {
+		sf := s.Type.Field(i)
+		if !sf.IsExported() || !isRelationField(sf) {
+			continue
+		}
+		rel, err := s.relation(sf.Name)
+		if err != nil {
+			return nil, err
+		}
+		relations = append(relations, rel)
+	}
+	return relations, nil
+}
+
+// isRelationField reports whether a struct field holds related models,
+// either declared with relation tags or typed as a model with db columns
+func isRelationField(sf reflect.StructField) bool {
+	if hasRelationTags(sf) {
+		return true
+	}
+
+	t := sf.Type
+	if t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct && len(schemaOf(t).Fields) > 0
+}
+
+// resolveRelation resolves the relation held by the field name of a model.
+// Without tags, naming conventions apply e.g. User.Posts is a has many
+// relation on posts.user_id and Post.User, given a Post.UserID field, is a
+// belongs to relation on posts.user_id.
+func resolveRelation(model reflect.Type, name string) (*relation, error) {
+	for model.Kind() == reflect.Pointer {
+		model = model.Elem()
+	}
+
+	sf, ok := model.FieldByName(name)
+	if !ok || len(sf.Index) != 1 {
+		return nil, fmt.Errorf("%s has no relation %q", model.Name(), name)
+	}
+
+	related := sf.Type
+	kind := hasOne
+	if related.Kind() == reflect.Slice {
+		related = related.Elem()
+		kind = hasMany
+	}
+	for related.Kind() == reflect.Pointer {
+		related = related.Elem()
+	}
+	if related.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%s.%s is not a relation", model.Name(), name)
+	}
+
+	options := parseTagOptions(sf.Tag.Get("goorm"))
+	modelSchema := schemaOf(model)
+	rel := &relation{
+		Name:     name,
+		Kind:     kind,
+		Field:    sf.Index[0],
+		Table:    sf.Tag.Get(DB_TAG),
+		OnDelete: options["ondelete"],
+		OnUpdate: options["onupdate"],
+		Schema:   schemaOf(related),
+	}
+	if rel.Table == "" {
+		rel.Table = rel.Schema.Table
+	}
+
+	var err error
+	if joinTable, ok := options["many2many"]; ok {
+		if kind != hasMany {
+			return nil, fmt.Errorf("%s.%s: many2many relations must be slices", model.Name(), name)
+		}
+		rel.Kind = manyToMany
+		rel.JoinTable = joinTable
+		rel.JoinForeignKey = joinColumn(options["joinforeignkey"], model)
+		rel.JoinReferences = joinColumn(options["joinreferences"], related)
+		if rel.ForeignKey, err = columnOf(modelSchema, options["foreignkey"], primaryKeyColumn(modelSchema)); err != nil {
+			return nil, err
+		}
+		if rel.References, err = columnOf(rel.Schema, options["references"], primaryKeyColumn(rel.Schema)); err != nil {
+			return nil, err
+		}
+		return rel, nil
+	}
+
+	foreignKey, hasForeignKey := options["foreignkey"]
+	if kind == hasOne {
+		// the relation belongs to the related model when the foreign key
+		// lives on the model instead of the related model
+		var fk *field
+		if hasForeignKey {
+			if rel.Schema.lookup(foreignKey) == nil {
+				fk = modelSchema.lookup(foreignKey)
+			}
+		} else {
+			fk = modelSchema.lookup(name + "ID")
+		}
+
+		if fk != nil {
+			rel.Kind = belongsTo
+			rel.ForeignKey = fk.Column
+			if rel.References, err = columnOf(rel.Schema, options["references"], primaryKeyColumn(rel.Schema)); err != nil {
+				return nil, err
+			}
+			return rel, nil
+		}
+	}
+
+	if rel.ForeignKey, err = columnOf(rel.Schema, foreignKey, toSnakeCase(model.Name())+"_id"); err != nil {
+		return nil, err
+	}
+	if rel.References, err = columnOf(modelSchema, options["references"], primaryKeyColumn(modelSchema)); err != nil {
+		return nil, err
+	}
+	return rel, nil
+}
+
+// columnOf returns the column of the field name, or def when name is empty
+func columnOf(s *schema, name string, def string) (string, error) {
+	if name == "" {
+		return def, nil
+	}
+	f := s.lookup(name)
+	if f == nil {
+		return "", fmt.Errorf("%s has no field %s", s.Type.Name(), name)
+	}
+	return f.Column, nil
+}
+
+// joinColumn returns the column of a join table, which defaults to the
+// model name followed by _id e.g. user_id
+func joinColumn(name string, model reflect.Type) string {
+	if name == "" {
+		return toSnakeCase(model.Name()) + "_id"
+	}
+	if strings.ToLower(name) != name {
+		return toSnakeCase(name)
+	}
+	return name
+}
+
+func primaryKeyColumn(s *schema) string {
+	if s.PrimaryKey != nil {
+		return s.PrimaryKey.Column
+	}
+	return "id"
+}
+
+// plural returns a naive plural form of a name e.g. category to categories
+func plural(name string) string {
+	switch {
+	case strings.HasSuffix(name, "y") && !strings.HasSuffix(name, "ay") &&
+		!strings.HasSuffix(name, "ey") && !strings.HasSuffix(name, "oy"):
+		return strings.TrimSuffix(name, "y") + "ies"
+	case strings.HasSuffix(name, "s") || strings.HasSuffix(name, "x") ||
+		strings.HasSuffix(name, "ch") || strings.HasSuffix(name, "sh"):
+		return name + "es"
+	}
+	return name + "s"
+}
+
+// ForeignKeys returns the foreign keys the relations of the models require,
+// grouped by the table they are created on. Has one and has many relations
+// add a foreign key to the related table, belongs to relations to the
+// table of the model and many to many relations to the join table.
+func ForeignKeys(models ...any) (map[string][]ForeignKey, error) {
+	foreignKeys := make(map[string][]ForeignKey)
+	seen := make(map[string]bool)
+
+	add := func(table, column, refTable, refColumn string, rel *relation) {
+		name := fmt.Sprintf("fk_%s_%s", table, column)
+		if seen[name] {
+			return
+		}
+		seen[name] = true
+
+		var options []string
+		if rel.OnDelete != "" {
+			options = append(options, "ON DELETE "+strings.ToUpper(rel.OnDelete))
+		}
+		if rel.OnUpdate != "" {
+			options = append(options, "ON UPDATE "+strings.ToUpper(rel.OnUpdate))
+		}
+
+		foreignKeys[table] = append(foreignKeys[table], ForeignKey{
+			Name:      name,
+			Column:    column,
+			RefTable:  refTable,
+			RefColumn: refColumn,
+			Options:   strings.Join(options, " "),
+		})
+	}
+
+	for _, model := range models {
+		s := schemaOf(reflect.TypeOf(model))
+		relations, err := s.relationGraph()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, rel := range relations {
+			switch rel.Kind {
+			case hasOne, hasMany:
+				add(rel.Table, rel.ForeignKey, s.Table, rel.References, rel)
+			case belongsTo:
+				add(s.Table, rel.ForeignKey, rel.Table, rel.References, rel)
+			case manyToMany:
+				add(rel.JoinTable, rel.JoinForeignKey, s.Table, rel.ForeignKey, rel)
+				add(rel.JoinTable, rel.JoinReferences, rel.Table, rel.References, rel)
+			}
+		}
+	}
+
+	for table, fks := range foreignKeys {
+		sort.SliceStable(fks, func(i, j int) bool {
+			return fks[i].Name < fks[j].Name
+		})
+		fks[len(fks)-1].Last = true
+		foreignKeys[table] = fks
+	}
+
+	return foreignKeys, nil
+}
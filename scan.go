@@ -0,0 +1,168 @@
+package goorm
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+)
+
+var scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+
+// isScalarType reports whether values of t are scanned from a single
+// column e.g. int64, string, []byte, time.Time or a sql.Scanner
+func isScalarType(t reflect.Type) bool {
+	if t == timeType || reflect.PointerTo(t).Implements(scannerType) {
+		return true
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return isScalarType(t.Elem())
+	case reflect.Slice:
+		return t.Elem().Kind() == reflect.Uint8
+	case reflect.Struct, reflect.Map, reflect.Array, reflect.Chan, reflect.Func, reflect.UnsafePointer:
+		return false
+	}
+	return true
+}
+
+// isTupleType reports whether t is a struct without db tags whose fields
+// are scanned by position, or a slice or array holding a whole row
+func isTupleType(t reflect.Type) bool {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		return !isScalarType(t) && len(schemaOf(t).Fields) == 0
+	case reflect.Slice, reflect.Array:
+		return !isScalarType(t)
+	}
+	return false
+}
+
+// mapToValues scans rows into targets that are not models: a scalar, a
+// map[string]T, a tuple or a slice of any of them. It returns false when
+// the target is a model or a slice of models.
+func mapToValues(rows *sql.Rows, target reflect.Value) (bool, error) {
+	isSlice := target.Kind() == reflect.Slice && !isScalarType(target.Type())
+	elemType := target.Type()
+	if isSlice {
+		elemType = elemType.Elem()
+	}
+
+	scan := valueScanner(elemType)
+	if scan == nil {
+		return false, nil
+	}
+
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return true, err
+	}
+
+	values := make([]interface{}, len(columns))
+	pointers := make([]interface{}, len(columns))
+	for i := range values {
+		pointers[i] = &values[i]
+	}
+
+	results := reflect.MakeSlice(reflect.SliceOf(elemType), 0, 0)
+	for rows.Next() {
+		if err := rows.Scan(pointers...); err != nil {
+			return true, err
+		}
+
+		dest := reflect.New(elemType).Elem()
+		if err := scan(columns, values, dest); err != nil {
+			return true, err
+		}
+
+		if !isSlice {
+			target.Set(dest)
+			break
+		}
+		results = reflect.Append(results, dest)
+	}
+
+	if err := rows.Err(); err != nil {
+		return true, err
+	}
+
+	if isSlice {
+		target.Set(results)
+	}
+	return true, nil
+}
+
+// valueScanner returns the function filling a value of type t from a row,
+// or nil when t is a model
+func valueScanner(t reflect.Type) func(columns []string, values []interface{}, dest reflect.Value) error {
+	switch {
+	case isScalarType(t):
+		return scanScalar
+	case t.Kind() == reflect.Map && t.Key().Kind() == reflect.String:
+		return scanMap
+	case isTupleType(t):
+		return scanTuple
+	}
+	return nil
+}
+
+func scanScalar(columns []string, values []interface{}, dest reflect.Value) error {
+	if len(columns) != 1 {
+		return fmt.Errorf("scanning into %s requires a single column, got %d", dest.Type(), len(columns))
+	}
+	return setFieldValue(dest, values[0])
+}
+
+func scanMap(columns []string, values []interface{}, dest reflect.Value) error {
+	m := reflect.MakeMapWithSize(dest.Type(), len(columns))
+	for i, column := range columns {
+		value := reflect.New(dest.Type().Elem()).Elem()
+		if err := setFieldValue(value, values[i]); err != nil {
+			return fmt.Errorf("column %s: %w", column, err)
+		}
+		m.SetMapIndex(reflect.ValueOf(column).Convert(dest.Type().Key()), value)
+	}
+	dest.Set(m)
+	return nil
+}
+
+func scanTuple(columns []string, values []interface{}, dest reflect.Value) error {
+	if dest.Kind() == reflect.Pointer {
+		dest.Set(reflect.New(dest.Type().Elem()))
+		dest = dest.Elem()
+	}
+
+	var fields []reflect.Value
+	switch dest.Kind() {
+	case reflect.Struct:
+		for i := 0; i < dest.NumField(); i++ {
+			if dest.Type().Field(i).IsExported() {
+				fields = append(fields, dest.Field(i))
+			}
+		}
+	case reflect.Slice:
+		dest.Set(reflect.MakeSlice(dest.Type(), len(columns), len(columns)))
+		fallthrough
+	case reflect.Array:
+		for i := 0; i < dest.Len(); i++ {
+			fields = append(fields, dest.Index(i))
+		}
+	}
+
+	if len(fields) != len(columns) {
+		return fmt.Errorf("scanning into %s requires %d columns, got %d", dest.Type(), len(fields), len(columns))
+	}
+
+	for i, f := range fields {
+		if err := setFieldValue(f, values[i]); err != nil {
+			return fmt.Errorf("column %s: %w", columns[i], err)
+		}
+	}
+	return nil
+}
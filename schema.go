@@ -0,0 +1,214 @@
+package goorm
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// field describes a struct field that is mapped to a database column
+type field struct {
+	Name    string
+	Index   int
+	Column  string
+	Type    reflect.Type
+	Options map[string]string
+}
+
+// schema holds the mapping metadata of a model struct
+type schema struct {
+	Type       reflect.Type
+	Table      string
+	Fields     []*field
+	PrimaryKey *field
+	columns    map[string]*field
+
+	mu        sync.Mutex
+	relations map[string]*relation
+}
+
+var schemaCache sync.Map
+
+// schemaOf returns the cached metadata of a struct type
+func schemaOf(t reflect.Type) *schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if s, ok := schemaCache.Load(t); ok {
+		return s.(*schema)
+	}
+
+	s := &schema{
+		Type:      t,
+		Table:     plural(toSnakeCase(t.Name())),
+		columns:   make(map[string]*field),
+		relations: make(map[string]*relation),
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+
+		column := sf.Tag.Get(DB_TAG)
+		if column == "" && isStructSlice(sf.Type) {
+			// has many relations are named after the field e.g. Posts -> posts
+			column = toSnakeCase(sf.Name)
+		}
+		if column == "" || column == "-" {
+			continue
+		}
+
+		f := &field{
+			Name:    sf.Name,
+			Index:   i,
+			Column:  column,
+			Type:    sf.Type,
+			Options: parseTagOptions(sf.Tag.Get("goorm")),
+		}
+		s.Fields = append(s.Fields, f)
+		s.columns[column] = f
+
+		if _, ok := f.Options["primary key"]; ok && s.PrimaryKey == nil {
+			s.PrimaryKey = f
+		}
+	}
+
+	if s.PrimaryKey == nil {
+		s.PrimaryKey = s.columns["id"]
+	}
+
+	actual, _ := schemaCache.LoadOrStore(t, s)
+	return actual.(*schema)
+}
+
+// hasColumn reports whether a field of the struct is mapped to column
+func (s *schema) hasColumn(column string) bool {
+	_, ok := s.columns[column]
+	return ok
+}
+
+// lookup returns the field with the Go name or column name
+func (s *schema) lookup(name string) *field {
+	for _, f := range s.Fields {
+		if f.Name == name {
+			return f
+		}
+	}
+	return s.columns[name]
+}
+
+// hasManyFields returns the fields holding a slice of structs
+func (s *schema) hasManyFields() []*field {
+	var fields []*field
+	for _, f := range s.Fields {
+		if isStructSlice(f.Type) {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+// primaryKeyOf returns the primary key of a struct value as a string,
+// ok is false when the struct has no primary key or it is not set
+func (s *schema) primaryKeyOf(v reflect.Value) (string, bool) {
+	if s.PrimaryKey == nil {
+		return "", false
+	}
+	pk := v.Field(s.PrimaryKey.Index)
+	if pk.IsZero() {
+		return "", false
+	}
+	return fmt.Sprint(pk.Interface()), true
+}
+
+// parseTagOptions parses a goorm tag e.g. `goorm:"primary key,default:30"`
+// into a map of option names and their values
+func parseTagOptions(tag string) map[string]string {
+	options := make(map[string]string)
+	for _, part := range splitTag(tag) {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, value, _ := strings.Cut(part, ":")
+		options[strings.ToLower(strings.TrimSpace(name))] = strings.TrimSpace(value)
+	}
+	return options
+}
+
+// splitTag splits a tag on commas and semicolons that are not inside
+// parentheses or quotes
+func splitTag(tag string) []string {
+	var parts []string
+	var current strings.Builder
+	depth := 0
+	inQuote := false
+
+	for _, char := range tag {
+		switch {
+		case char == '\'':
+			inQuote = !inQuote
+		case char == '(' && !inQuote:
+			depth++
+		case char == ')' && !inQuote:
+			depth--
+		case (char == ',' || char == ';') && !inQuote && depth == 0:
+			parts = append(parts, current.String())
+			current.Reset()
+			continue
+		}
+		current.WriteRune(char)
+	}
+
+	if current.Len() > 0 {
+		parts = append(parts, current.String())
+	}
+	return parts
+}
+
+func isStructSlice(t reflect.Type) bool {
+	if t.Kind() != reflect.Slice {
+		return false
+	}
+	elem := t.Elem()
+	if elem.Kind() == reflect.Pointer {
+		elem = elem.Elem()
+	}
+	return elem.Kind() == reflect.Struct
+}
+
+// toSnakeCase converts a Go identifier e.g. UserID to user_id
+func toSnakeCase(name string) string {
+	runes := []rune(name)
+	var b strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 && (unicode.IsLower(runes[i-1]) ||
+				(i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+				b.WriteRune('_')
+			}
+			b.WriteRune(unicode.ToLower(r))
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// singular returns a naive singular form of a table name e.g. posts to post
+func singular(name string) string {
+	switch {
+	case strings.HasSuffix(name, "ies"):
+		return strings.TrimSuffix(name, "ies") + "y"
+	case strings.HasSuffix(name, "sses"):
+		return strings.TrimSuffix(name, "es")
+	case strings.HasSuffix(name, "s") && !strings.HasSuffix(name, "ss"):
+		return strings.TrimSuffix(name, "s")
+	}
+	return name
+}
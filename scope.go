@@ -0,0 +1,38 @@
+package goorm
+
+// Scope is a reusable part of a query applied with Scopes e.g.
+//
+//	func Active(q *goorm.QueryBuilder) {
+//		q.Where("active = ?", true)
+//	}
+//
+//	func CreatedAfter(t time.Time) goorm.Scope {
+//		return func(q *goorm.QueryBuilder) {
+//			q.Where("created_at > ?", t)
+//		}
+//	}
+type Scope func(q *QueryBuilder)
+
+// Scopes applies the scopes to the query in order, the conditions they add
+// with Where are joined with AND
+// e.g. qb.Select().From("users").Scopes(Active, CreatedAfter(t), TenantScope(id))
+func (q *QueryBuilder) Scopes(scopes ...Scope) *QueryBuilder {
+	for _, scope := range scopes {
+		scope(q)
+	}
+	return q
+}
+
+// When applies fn to the query only when condition is true, which keeps
+// optional filters in the chain
+// e.g.
+//
+//	qb.Select().From("users").
+//		When(filter.Name != "", func(q *goorm.QueryBuilder) { q.Where("name = ?", filter.Name) }).
+//		When(filter.Email != "", func(q *goorm.QueryBuilder) { q.Where("email = ?", filter.Email) })
+func (q *QueryBuilder) When(condition bool, fn Scope) *QueryBuilder {
+	if condition {
+		fn(q)
+	}
+	return q
+}
@@ -0,0 +1,53 @@
+package goorm
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Union combines the rows of the query with the distinct rows of other.
+// The placeholders of other are numbered after the ones of the query and
+// OrderBy, Limit and Offset called afterwards apply to the combined rows
+// e.g.
+//
+//	qb.Select("name").From("users").Where("id = $1", 1).
+//		Union(other.Select("name").From("admins").Where("id = $1", 2)).
+//		OrderBy("name").Limit(10)
+func (q *QueryBuilder) Union(other *QueryBuilder) *QueryBuilder {
+	return q.setOperation("UNION", other)
+}
+
+// UnionAll combines the rows of the query with all rows of other
+func (q *QueryBuilder) UnionAll(other *QueryBuilder) *QueryBuilder {
+	return q.setOperation("UNION ALL", other)
+}
+
+// Intersect keeps the rows of the query that other returns as well
+func (q *QueryBuilder) Intersect(other *QueryBuilder) *QueryBuilder {
+	return q.setOperation("INTERSECT", other)
+}
+
+// Except keeps the rows of the query that other does not return
+func (q *QueryBuilder) Except(other *QueryBuilder) *QueryBuilder {
+	return q.setOperation("EXCEPT", other)
+}
+
+func (q *QueryBuilder) setOperation(operation string, other *QueryBuilder) *QueryBuilder {
+	if !supportsSetOperation(q.Dialect, operation) {
+		q.err = fmt.Errorf("%s does not support %s", dialectName(q.Dialect), operation)
+		other.Reset()
+		return q
+	}
+	if other.err != nil {
+		q.err = other.err
+		other.Reset()
+		return q
+	}
+
+	query, params := other.subquery(len(q.params))
+	q.query.WriteString(" " + operation + " " + strings.TrimSuffix(strings.TrimSpace(query), ";"))
+	q.params = append(q.params, params...)
+	q.embedded += len(params)
+	q.operations = append(q.operations, operation)
+	return q
+}
@@ -7,7 +7,11 @@ import (
 )
 
 // SQLite dialect implementation
-type SQLite struct{}
+type SQLite struct {
+	// Version is the library version e.g. 3.45.1, features of the latest
+	// version are used when it is empty
+	Version string
+}
 
 func (s *SQLite) GetName() Driver {
 	return "sqlite3"
@@ -1,30 +1,37 @@
 package goorm
 
 type User struct {
-	ID      int      `db_col:"id" goorm:"primary key,auto_increment,type:serial"`
-	Name    string   `db_col:"name"`
-	Email   string   `db_col:"email"`
-	Age     int64    `db_col:"age" goorm:"default:30,check:(age > 0)"`
-	Profile *Profile // Has one relationship
-	Posts   []Post   // Has many relationship
+	ID      int      `db:"id" goorm:"primary key,auto_increment,type:serial"`
+	Name    string   `db:"name"`
+	Email   string   `db:"email"`
+	Age     int64    `db:"age" goorm:"default:30,check:(age > 0)"`
+	Profile *Profile `goorm:"foreignKey:UserID;references:ID;onDelete:CASCADE"`
+	Posts   []Post   `goorm:"foreignKey:UserID;references:ID;onDelete:CASCADE"`
+	Roles   []Role   `goorm:"many2many:user_roles"`
 }
 
 type Profile struct {
-	ID     int `db_col:"id" goorm:"primary key,auto_increment,type:serial"`
-	UserID int    `db_col:"user_id"`
-	Bio    string `db_col:"bio"`
-	User   *User  // Belongs to relationship
+	ID     int    `db:"id" goorm:"primary key,auto_increment,type:serial"`
+	UserID int    `db:"user_id"`
+	Bio    string `db:"bio"`
+	User   *User  `goorm:"foreignKey:UserID;references:ID"`
 }
 
 type Post struct {
-	ID      int `db_col:"id" goorm:"primary key,auto_increment,type:serial"`
-	Title   string `db_col:"title" goorm:"unique,default:'asdasd'"`
-	Content string `db_col:"content"`
-	UserID  int    `db_col:"user_id"`
-	User    *User  // Belongs to relationship
+	ID      int    `db:"id" goorm:"primary key,auto_increment,type:serial"`
+	Title   string `db:"title" goorm:"unique,default:'asdasd'"`
+	Content string `db:"content"`
+	UserID  int    `db:"user_id"`
+	User    *User  `goorm:"foreignKey:UserID;references:ID"`
 }
 
 type Comment struct {
-	ID      int `db_col:"id" goorm:"primary key,auto_increment,type:serial"`
-	Content string `db_col:"content"`
+	ID      int    `db:"id" goorm:"primary key,auto_increment,type:serial"`
+	Content string `db:"content"`
+}
+
+type Role struct {
+	ID    int    `db:"id" goorm:"primary key,auto_increment,type:serial"`
+	Name  string `db:"name" goorm:"unique"`
+	Users []User `goorm:"many2many:user_roles"`
 }
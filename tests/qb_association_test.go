@@ -0,0 +1,117 @@
+package tests_test
+
+import (
+	"context"
+	"testing"
+
+	orm "github.com/patrickkabwe/goorm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryBuilderManyToManyAssociation(t *testing.T) {
+	ctx := context.Background()
+	u, err := createUser(ctx, qb)
+	if err != nil {
+		t.Errorf("failed %v", err)
+	}
+
+	admin, err := createRole(ctx, qb, "admin")
+	if err != nil {
+		t.Errorf("failed %v", err)
+	}
+	editor, err := createRole(ctx, qb, "editor")
+	if err != nil {
+		t.Errorf("failed %v", err)
+	}
+
+	err = qb.Association(u, "Roles").Append(ctx, admin, editor)
+	if assert.NoError(t, err) {
+		assert.Len(t, u.Roles, 2)
+	}
+
+	user := &User{}
+	err = qb.Select().From("users").Where("users.id = $1", u.ID).Preload("Roles").Scan(ctx, user)
+	if assert.NoError(t, err) {
+		assert.Len(t, user.Roles, 2)
+	}
+
+	err = qb.Association(u, "Roles").Remove(ctx, editor)
+	if assert.NoError(t, err) && assert.Len(t, u.Roles, 1) {
+		assert.Equal(t, admin.ID, u.Roles[0].ID)
+	}
+
+	err = qb.Association(u, "Roles").Replace(ctx, editor)
+	if assert.NoError(t, err) && assert.Len(t, u.Roles, 1) {
+		assert.Equal(t, editor.ID, u.Roles[0].ID)
+	}
+}
+
+func TestForeignKeysFromRelations(t *testing.T) {
+	fks, err := orm.ForeignKeys(User{}, Post{})
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "user_id", fks["posts"][0].Column)
+		assert.Equal(t, "users", fks["posts"][0].RefTable)
+		assert.Len(t, fks["user_roles"], 2)
+		assert.Equal(t, "post_id", fks["comments"][0].Column)
+	}
+}
+
+func TestQueryBuilderSaveAssociations(t *testing.T) {
+	ctx := context.Background()
+	admin, err := createRole(ctx, qb, "admin")
+	if err != nil {
+		t.Errorf("failed %v", err)
+	}
+
+	user := &User{
+		Name:    "cascade",
+		Email:   "cascade@gmail.com",
+		Profile: &Profile{Avatar: "cascade_url"},
+		Posts:   []Post{{Body: "first"}, {Body: "second"}},
+		Roles:   []Role{*admin, {Name: "reviewer"}},
+	}
+	err = qb.InsertInto("users").Model(user).Save(ctx)
+	if assert.NoError(t, err) {
+		assert.NotZero(t, user.ID)
+		assert.Equal(t, user.ID, user.Profile.UserID)
+		assert.Equal(t, user.ID, user.Posts[1].UserID)
+		assert.NotZero(t, user.Roles[1].ID)
+	}
+
+	saved := &User{}
+	err = qb.
+		Select().
+		From("users").
+		Where("users.id = $1", user.ID).
+		Preload("Profile").
+		Preload("Posts").
+		Preload("Roles").
+		Scan(ctx, saved)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "cascade_url", saved.Profile.Avatar)
+		assert.Len(t, saved.Posts, 2)
+		assert.Len(t, saved.Roles, 2)
+	}
+
+	saved.Posts[0].Body = "edited"
+	err = qb.Update("users").SetModel(saved, "Name").Where("id = $2", saved.ID).Save(ctx)
+	assert.NoError(t, err)
+
+	var bodies []string
+	err = qb.Select("body").From("posts").Where("user_id = $1", user.ID).OrderBy("id").Scan(ctx, &bodies)
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{"edited", "second"}, bodies)
+	}
+}
+
+func TestQueryBuilderRemoveWithoutValues(t *testing.T) {
+	ctx := context.Background()
+	u, err := createUser(ctx, qb)
+	if err != nil {
+		t.Errorf("failed %v", err)
+	}
+
+	assert.NoError(t, qb.Association(u, "Roles").Remove(ctx))
+	assert.NoError(t, qb.Association(u, "Posts").Remove(ctx))
+}
@@ -0,0 +1,95 @@
+package tests_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	orm "github.com/patrickkabwe/goorm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryBuilderBatchInsert(t *testing.T) {
+	ctx := context.Background()
+
+	roles := []Role{}
+	err := qb.
+		InsertInto("roles").
+		Columns("name").
+		Values("reader").
+		Values("writer").
+		Returning(ctx, &roles, "id", "name")
+	if assert.NoError(t, err) && assert.Len(t, roles, 2) {
+		assert.Equal(t, "reader", roles[0].Name)
+		assert.Equal(t, "writer", roles[1].Name)
+	}
+
+	users := []User{}
+	err = qb.
+		InsertInto("users").
+		Columns("name", "email").
+		Values([][]any{
+			{"john", "john@gmail.com"},
+			{"jane", "jane@gmail.com"},
+			{"jim", "jim@gmail.com"},
+		}).
+		Returning(ctx, &users, "id", "name")
+	if assert.NoError(t, err) && assert.Len(t, users, 3) {
+		assert.NotZero(t, users[2].ID)
+		assert.Equal(t, "jim", users[2].Name)
+	}
+}
+
+func TestQueryBuilderBatchInsertRollsBack(t *testing.T) {
+	ctx := context.Background()
+	role, err := createRole(ctx, qb, "existing")
+	if err != nil {
+		t.Errorf("failed %v", err)
+	}
+
+	// more rows than one statement can bind, the last one conflicts with
+	// the existing role in the second batch
+	rows := make([][]any, 40000)
+	for i := range rows {
+		rows[i] = []any{1000000 + i, fmt.Sprintf("rollback-%d", i)}
+	}
+	rows[len(rows)-1][0] = role.ID
+
+	result, err := qb.InsertInto("roles").Columns("id", "name").Values(rows).Exec(ctx)
+	if !assert.Error(t, err) {
+		result.Close()
+	}
+
+	var count int64
+	err = qb.Select("COUNT(*)").From("roles").Where("name LIKE 'rollback-%'").Scan(ctx, &count)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), count)
+}
+
+func TestQueryBuilderBatchInsertReturningFallback(t *testing.T) {
+	ctx := context.Background()
+	for _, test := range []struct {
+		dialect orm.Dialect
+		first   int64
+	}{
+		// MySQL returns the id of the first inserted row, SQLite the one
+		// of the last
+		{&orm.MYSQL{}, 5},
+		{&orm.SQLite{}, 7},
+	} {
+		r, conn := newRecorder()
+		r.insertIDs = []int64{test.first}
+		r.queue([]string{"id", "name"}, []any{int64(5), "reader"}, []any{int64(6), "writer"}, []any{int64(7), "owner"})
+
+		roles := []Role{}
+		err := orm.NewQueryBuilder(conn, test.dialect, nil).
+			InsertInto("roles").
+			Columns("name").
+			Values([][]any{{"reader"}, {"writer"}, {"owner"}}).
+			Returning(ctx, &roles, "id", "name")
+		if assert.NoError(t, err) && assert.Len(t, r.args, 2) {
+			assert.Equal(t, []any{int64(5)}, r.args[1])
+			assert.Equal(t, []Role{{ID: 5, Name: "reader"}, {ID: 6, Name: "writer"}, {ID: 7, Name: "owner"}}, roles)
+		}
+	}
+}
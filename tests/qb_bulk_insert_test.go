@@ -0,0 +1,85 @@
+package tests_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	orm "github.com/patrickkabwe/goorm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBulkInsert(t *testing.T) {
+	ctx := context.Background()
+
+	roles := make([]Role, 100)
+	for i := range roles {
+		roles[i].Name = fmt.Sprintf("bulk-%d", i)
+	}
+	n, err := orm.BulkInsert(ctx, qb, "roles", nil, roles)
+	if assert.NoError(t, err) {
+		assert.Equal(t, int64(100), n)
+	}
+
+	n, err = orm.BulkInsert(ctx, qb, "roles", []string{"name"}, [][]any{{"bulk-a"}, {"bulk-b"}})
+	if assert.NoError(t, err) {
+		assert.Equal(t, int64(2), n)
+	}
+
+	var count int64
+	err = qb.Select("COUNT(*)").From("roles").Where("name LIKE 'bulk-%'").Scan(ctx, &count)
+	assert.NoError(t, err)
+	assert.GreaterOrEqual(t, count, int64(102))
+}
+
+func TestBulkInsertBatches(t *testing.T) {
+	ctx := context.Background()
+
+	// Kind has no column, the database would generate it
+	type bulkRole struct {
+		ID   int64  `db:"id"`
+		Name string `db:"name"`
+		Kind string `db:"kind" goorm:"default"`
+	}
+	roles := []bulkRole{{Name: "batch-a"}, {Name: "batch-b"}, {Name: "batch-c"}}
+
+	// transactions cannot COPY, the rows are inserted in batches
+	err := qb.Transaction(ctx, func(tx *orm.QueryBuilder) error {
+		n, err := orm.BulkInsert(ctx, tx, "roles", nil, roles)
+		assert.Equal(t, int64(3), n)
+		return err
+	})
+	assert.NoError(t, err)
+
+	var count int64
+	err = qb.Select("COUNT(*)").From("roles").Where("name LIKE 'batch-%'").Scan(ctx, &count)
+	assert.NoError(t, err)
+	assert.GreaterOrEqual(t, count, int64(3))
+}
+
+func TestBulkInsertBatchesRollBack(t *testing.T) {
+	ctx := context.Background()
+	r, conn := newRecorder()
+
+	// SQLite before 3.32 binds at most 999 parameters, the last row fails
+	// after the first batch is inserted
+	rows := make([][]any, 1000)
+	for i := range rows {
+		rows[i] = []any{fmt.Sprintf("rollback-%d", i)}
+	}
+	rows[len(rows)-1] = append(rows[len(rows)-1], "extra")
+
+	q := orm.NewQueryBuilder(conn, &orm.SQLite{Version: "3.20"}, nil)
+	n, err := orm.BulkInsert(ctx, q, "roles", []string{"name"}, rows)
+	assert.EqualError(t, err, "bulk insert: row has 2 values for 1 columns")
+	assert.Equal(t, int64(0), n)
+	assert.Len(t, r.statements, 1)
+	assert.Equal(t, 0, r.commits)
+	assert.Equal(t, 1, r.rollbacks)
+
+	n, err = orm.BulkInsert(ctx, q, "roles", []string{"name"}, rows[:len(rows)-1])
+	if assert.NoError(t, err) {
+		assert.Equal(t, int64(999), n)
+		assert.Equal(t, 1, r.commits)
+	}
+}
@@ -0,0 +1,83 @@
+package tests_test
+
+import (
+	"context"
+	"testing"
+
+	orm "github.com/patrickkabwe/goorm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryBuilderCase(t *testing.T) {
+	ctx := context.Background()
+	u, err := createUser(ctx, qb)
+	if err != nil {
+		t.Errorf("failed %v", err)
+	}
+	for _, body := range []string{"draft", "published", "archived"} {
+		if _, err := createPost(ctx, qb, u.ID, body); err != nil {
+			t.Errorf("failed %v", err)
+		}
+	}
+
+	type labeledPost struct {
+		Body  string `db:"body"`
+		Label string `db:"label"`
+	}
+
+	var posts []labeledPost
+	err = qb.
+		Select(
+			"body",
+			orm.Case("body").When("draft", "hidden").When("published", "visible").Else("other").End().As("label"),
+		).
+		From("posts").
+		Where("user_id = $1", u.ID).
+		OrderBy(orm.Case().When("body = 'published'", 0).Else(1).End(), "id").
+		Scan(ctx, &posts)
+	if assert.NoError(t, err) {
+		assert.Equal(t, []labeledPost{
+			{Body: "published", Label: "visible"},
+			{Body: "draft", Label: "hidden"},
+			{Body: "archived", Label: "other"},
+		}, posts)
+	}
+}
+
+func TestQueryBuilderCaseInSetAndWhere(t *testing.T) {
+	ctx := context.Background()
+	u, err := createUser(ctx, qb)
+	if err != nil {
+		t.Errorf("failed %v", err)
+	}
+
+	user := &User{}
+	err = qb.
+		Update("users").
+		Set("name", orm.Case().When("email LIKE '%@gail.com'", "gail").Else("other").End()).
+		Where("id = $1", u.ID).
+		Returning(ctx, user, "name")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "gail", user.Name)
+	}
+
+	sql := orm.NewQueryBuilder(nil, &orm.PostgreSQL{}, nil).
+		Select("id").
+		From("users").
+		Where("id = $1", u.ID).
+		And(orm.Case("name").When("gail", "yes").Else("no").End()).
+		GetSql()
+	assert.Equal(t, `SELECT "users"."id" FROM "users" WHERE "users"."id" = $1 AND CASE name WHEN $2 THEN $3 ELSE $4 END;`, sql)
+}
+
+func TestQueryBuilderCaseStartSQL(t *testing.T) {
+	sql := orm.NewQueryBuilder(nil, &orm.PostgreSQL{}, nil).
+		Select("id").
+		CaseStart().
+		CaseWhen("id > 10", "'big'").
+		CaseElse("'small'").
+		CaseEnd().
+		From("users").
+		GetSql()
+	assert.Equal(t, `SELECT "users"."id", CASE WHEN id > 10 THEN 'big' ELSE 'small' END FROM "users";`, sql)
+}
@@ -0,0 +1,93 @@
+package tests_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	orm "github.com/patrickkabwe/goorm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryBuilderChunk(t *testing.T) {
+	ctx := context.Background()
+	for i := 0; i < 5; i++ {
+		if _, err := createUser(ctx, qb); err != nil {
+			t.Errorf("failed %v", err)
+		}
+	}
+
+	var lastID int64
+	err := orm.Chunk(ctx, qb.Select("id", "name", "email").From("users"), 2, func(users []User) error {
+		assert.LessOrEqual(t, len(users), 2)
+		for _, user := range users {
+			assert.Greater(t, user.ID, lastID)
+			lastID = user.ID
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+
+	stop := errors.New("stop")
+	batches := 0
+	err = orm.ChunkByID(ctx, qb.Select("id", "name", "email").From("users"), "users.id", 2, func(users []User) error {
+		batches++
+		return stop
+	})
+	assert.ErrorIs(t, err, stop)
+	assert.Equal(t, 1, batches)
+}
+
+func TestQueryBuilderChunkInTransaction(t *testing.T) {
+	ctx := context.Background()
+	u, err := createUser(ctx, qb)
+	if err != nil {
+		t.Errorf("failed %v", err)
+	}
+	other, err := createUser(ctx, qb)
+	if err != nil {
+		t.Errorf("failed %v", err)
+	}
+
+	fail := errors.New("fail")
+	err = orm.ChunkTx(ctx, qb.Select("id", "name", "email").From("users").Where("id = $1", u.ID), 10, func(tx *orm.QueryBuilder, users []User) error {
+		rows, err := tx.Delete("users").Where("id = $1", u.ID).Exec(ctx)
+		if err != nil {
+			return err
+		}
+		rows.Close()
+
+		// qb is not part of the transaction of the batch
+		rows, err = qb.Update("users").Set("name", "outside").Where("id = $2", other.ID).Exec(ctx)
+		if err != nil {
+			return err
+		}
+		rows.Close()
+		return fail
+	})
+	assert.ErrorIs(t, err, fail)
+
+	var count int64
+	err = qb.Select("COUNT(*)").From("users").Where("id = $1", u.ID).Scan(ctx, &count)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+
+	var name string
+	err = qb.Select("name").From("users").Where("id = $1", other.ID).Scan(ctx, &name)
+	assert.NoError(t, err)
+	assert.Equal(t, "outside", name)
+}
+
+func TestQueryBuilderChunkRejectsClauses(t *testing.T) {
+	ctx := context.Background()
+	noop := func(users []User) error { return nil }
+
+	err := orm.Chunk(ctx, qb.Select("id", "name").From("users").GroupBy("id", "name"), 10, noop)
+	assert.EqualError(t, err, "chunk: cannot page a query with GROUP BY")
+
+	err = orm.Chunk(ctx, qb.Select("id", "name").From("users").OrderBy("name"), 10, noop)
+	assert.EqualError(t, err, "chunk: cannot page a query with ORDER BY")
+
+	err = orm.Chunk(ctx, qb.Select("id", "name").From("users").ForUpdate(), 10, noop)
+	assert.EqualError(t, err, "chunk: cannot page a query with a locking clause")
+}
@@ -0,0 +1,50 @@
+package tests_test
+
+import (
+	"context"
+	"testing"
+
+	orm "github.com/patrickkabwe/goorm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryBuilderWith(t *testing.T) {
+	ctx := context.Background()
+	u, err := createUser(ctx, qb)
+	if err != nil {
+		t.Errorf("failed %v", err)
+	}
+
+	selected := orm.NewQueryBuilder(db, &orm.PostgreSQL{}, nil).
+		Select("id").
+		From("users").
+		Where("id = $1", u.ID)
+
+	var names []string
+	err = qb.
+		With("selected_users", selected).
+		Select("name").
+		From("users").
+		Where("id IN (SELECT id FROM selected_users) AND name = $1", u.Name).
+		Scan(ctx, &names)
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{u.Name}, names)
+	}
+
+	selected = orm.NewQueryBuilder(db, &orm.PostgreSQL{}, nil).
+		Select("id").
+		From("users").
+		Where("id = $1", u.ID)
+
+	user := &User{}
+	err = qb.
+		With("selected_users", selected).
+		Update("users").
+		Set("name", "cte").
+		Where("id IN (SELECT id FROM selected_users)").
+		Returning(ctx, user, "id", "name")
+	if assert.NoError(t, err) {
+		assert.Equal(t, u.ID, user.ID)
+		assert.Equal(t, "cte", user.Name)
+	}
+}
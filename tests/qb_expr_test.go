@@ -0,0 +1,69 @@
+package tests_test
+
+import (
+	"context"
+	"testing"
+
+	orm "github.com/patrickkabwe/goorm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryBuilderExpr(t *testing.T) {
+	ctx := context.Background()
+	u, err := createUser(ctx, qb)
+	if err != nil {
+		t.Errorf("failed %v", err)
+	}
+
+	type userKey struct {
+		Key  string `db:"key"`
+		Name string `db:"name"`
+	}
+
+	var keys []userKey
+	err = qb.
+		Select(orm.Expr("id::text AS key"), "name").
+		From("users").
+		Where("id = ?", u.ID).
+		Where(orm.Expr("COALESCE(NULLIF(name, ''), 'x') = ?", u.Name)).
+		Scan(ctx, &keys)
+	if assert.NoError(t, err) && assert.Len(t, keys, 1) {
+		assert.Equal(t, u.Name, keys[0].Name)
+		assert.NotEmpty(t, keys[0].Key)
+	}
+
+	user := &User{}
+	err = qb.
+		Update("users").
+		Set("name", orm.Expr("name || ?", "-expr")).
+		Where("id = ?", u.ID).
+		Returning(ctx, user, "name")
+	if assert.NoError(t, err) {
+		assert.Equal(t, u.Name+"-expr", user.Name)
+	}
+}
+
+func TestQueryBuilderIdent(t *testing.T) {
+	ctx := context.Background()
+	u, err := createUser(ctx, qb)
+	if err != nil {
+		t.Errorf("failed %v", err)
+	}
+
+	var names []string
+	err = qb.
+		Select(orm.Ident("users.name")).
+		From(orm.Ident("public.users")).
+		Where(orm.Expr(`"users"."id" = ?`, u.ID)).
+		OrderBy(orm.Ident("users.id")).
+		Scan(ctx, &names)
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{u.Name}, names)
+	}
+
+	sql := orm.NewQueryBuilder(nil, &orm.MYSQL{}, nil).
+		Select(orm.Ident("order"), orm.Ident("group")).
+		From("events").
+		GetSql()
+	assert.Equal(t, "SELECT `order`, `group` FROM `events`;", sql)
+}
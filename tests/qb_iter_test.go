@@ -0,0 +1,43 @@
+package tests_test
+
+import (
+	"context"
+	"testing"
+
+	orm "github.com/patrickkabwe/goorm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryBuilderIter(t *testing.T) {
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		if _, err := createUser(ctx, qb); err != nil {
+			t.Errorf("failed %v", err)
+		}
+	}
+
+	count := 0
+	for user, err := range orm.Iter[User](ctx, qb.Select("id", "name", "email").From("users").OrderBy("id")) {
+		assert.NoError(t, err)
+		assert.NotZero(t, user.ID)
+		count++
+		if count == 2 {
+			break
+		}
+	}
+	assert.Equal(t, 2, count)
+
+	cursor, err := orm.NewCursor[int64](ctx, qb.Select("id").From("users"))
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer cursor.Close()
+
+	ids := 0
+	for cursor.Next() {
+		assert.NotZero(t, cursor.Value())
+		ids++
+	}
+	assert.NoError(t, cursor.Err())
+	assert.GreaterOrEqual(t, ids, 3)
+}
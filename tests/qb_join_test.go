@@ -0,0 +1,94 @@
+package tests_test
+
+import (
+	"context"
+	"testing"
+
+	orm "github.com/patrickkabwe/goorm"
+	"github.com/stretchr/testify/assert"
+)
+
+type userAvatar struct {
+	ID     int64  `db:"id"`
+	Avatar string `db:"avatar"`
+}
+
+func TestQueryBuilderJoinAlias(t *testing.T) {
+	ctx := context.Background()
+	u, err := createUser(ctx, qb)
+	if err != nil {
+		t.Errorf("failed %v", err)
+	}
+	if err := createProfile(ctx, qb, u.ID); err != nil {
+		t.Errorf("failed %v", err)
+	}
+
+	var result []userAvatar
+	err = qb.
+		Select("id", "profiles.avatar").
+		From("users u").
+		LeftJoin(orm.Ref("profiles").As("p"), orm.On("users.id", "p.user_id").And("p.avatar = $1", "some_url")).
+		Where("id = $1", u.ID).
+		Scan(ctx, &result)
+	if assert.NoError(t, err) {
+		assert.Equal(t, []userAvatar{{ID: u.ID, Avatar: "some_url"}}, result)
+	}
+
+	result = nil
+	err = qb.
+		Select("id", "p.avatar").
+		From("users").
+		LeftJoin(orm.Ref("profiles").As("p"), orm.On("users.id", "p.user_id").And("p.avatar = $1", "other_url")).
+		Where("id = $1", u.ID).
+		Scan(ctx, &result)
+	if assert.NoError(t, err) {
+		assert.Equal(t, []userAvatar{{ID: u.ID}}, result)
+	}
+}
+
+func TestQueryBuilderLateralJoin(t *testing.T) {
+	ctx := context.Background()
+	u, err := createUser(ctx, qb)
+	if err != nil {
+		t.Errorf("failed %v", err)
+	}
+	for _, body := range []string{"older", "latest"} {
+		if _, err := createPost(ctx, qb, u.ID, body); err != nil {
+			t.Errorf("failed %v", err)
+		}
+	}
+
+	latest := orm.NewQueryBuilder(db, &orm.PostgreSQL{}, nil).
+		Select("body").
+		From("posts").
+		Where("posts.user_id = users.id").
+		OrderBy("posts.id DESC").
+		Limit(1)
+
+	var bodies []string
+	err = qb.
+		Select("l.body").
+		From("users").
+		CrossJoin(orm.Lateral(latest).As("l")).
+		Where("id = $1", u.ID).
+		Scan(ctx, &bodies)
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{"latest"}, bodies)
+	}
+}
+
+func TestQueryBuilderJoinSQL(t *testing.T) {
+	sql := orm.NewQueryBuilder(nil, &orm.PostgreSQL{}, nil).
+		Select("id").
+		From("users").
+		FullJoin("accounts", orm.Using("id", "tenant_id")).
+		GetSql()
+	assert.Equal(t, `SELECT "users"."id" FROM "users" FULL JOIN "accounts" USING ("id", "tenant_id");`, sql)
+
+	_, err := orm.NewQueryBuilder(nil, &orm.MYSQL{}, nil).
+		Select("id").
+		From("users").
+		FullJoin("accounts", "accounts.id = users.id").
+		Exec(context.Background())
+	assert.EqualError(t, err, "MySQL does not support FULL JOIN")
+}
@@ -0,0 +1,85 @@
+package tests_test
+
+import (
+	"context"
+	"testing"
+
+	orm "github.com/patrickkabwe/goorm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryBuilderForUpdate(t *testing.T) {
+	ctx := context.Background()
+	u, err := createUser(ctx, qb)
+	if err != nil {
+		t.Errorf("failed %v", err)
+	}
+
+	err = qb.Transaction(ctx, func(tx *orm.QueryBuilder) error {
+		var locked []int64
+		err := tx.
+			Select("id").
+			From("users").
+			Where("id = $1", u.ID).
+			ForUpdate().
+			Scan(ctx, &locked)
+		if !assert.NoError(t, err) || !assert.Equal(t, []int64{u.ID}, locked) {
+			return err
+		}
+
+		// another transaction skips the locked row or fails right away
+		other := orm.NewQueryBuilder(db, &orm.PostgreSQL{}, nil)
+		err = other.Transaction(ctx, func(tx *orm.QueryBuilder) error {
+			var skipped []int64
+			err := tx.
+				Select("id").
+				From("users").
+				Where("id = $1", u.ID).
+				ForUpdate().
+				SkipLocked().
+				Scan(ctx, &skipped)
+			if assert.NoError(t, err) {
+				assert.Empty(t, skipped)
+			}
+
+			var ids []int64
+			return tx.
+				Select("id").
+				From("users").
+				Where("id = $1", u.ID).
+				ForShare().
+				NoWait().
+				Scan(ctx, &ids)
+		})
+		assert.Error(t, err)
+		return nil
+	})
+	assert.NoError(t, err)
+}
+
+func TestQueryBuilderForUpdateOf(t *testing.T) {
+	sql := orm.NewQueryBuilder(nil, &orm.PostgreSQL{}, nil).
+		Select("id").
+		From("posts").
+		Where("user_id = $1", 1).
+		ForUpdate().
+		Of("posts").
+		SkipLocked().
+		Limit(5).
+		GetSql()
+	assert.Equal(t, `SELECT "posts"."id" FROM "posts" WHERE "posts"."user_id" = $1 LIMIT 5 FOR UPDATE OF "posts" SKIP LOCKED;`, sql)
+
+	sql = orm.NewQueryBuilder(nil, &orm.MYSQL{Version: "5.7"}, nil).
+		Select("id").
+		From("posts").
+		ForShare().
+		GetSql()
+	assert.Equal(t, "SELECT `posts`.`id` FROM `posts` LOCK IN SHARE MODE;", sql)
+
+	sql = orm.NewQueryBuilder(nil, &orm.SQLite{}, nil).
+		Select("id").
+		From("posts").
+		ForUpdate().
+		GetSql()
+	assert.Equal(t, `SELECT "posts"."id" FROM "posts";`, sql)
+}
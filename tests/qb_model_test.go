@@ -0,0 +1,57 @@
+package tests_test
+
+import (
+	"context"
+	"testing"
+
+	orm "github.com/patrickkabwe/goorm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryBuilderInsertModel(t *testing.T) {
+	ctx := context.Background()
+
+	user := &User{Name: "model", Email: "model@gmail.com"}
+	err := qb.InsertInto("users").Model(user).Save(ctx)
+	if assert.NoError(t, err) {
+		assert.NotZero(t, user.ID)
+		assert.Equal(t, "model", user.Name)
+	}
+
+	roles := []Role{{Name: "auditor"}, {Name: "owner"}}
+	err = qb.InsertInto("roles").Model(&roles).Save(ctx)
+	if assert.NoError(t, err) {
+		assert.NotZero(t, roles[0].ID)
+		assert.NotZero(t, roles[1].ID)
+		assert.Equal(t, "owner", roles[1].Name)
+	}
+}
+
+func TestQueryBuilderUpdateModel(t *testing.T) {
+	ctx := context.Background()
+	user := &User{Name: "before", Email: "before@gmail.com"}
+	if err := qb.InsertInto("users").Model(user).Save(ctx); err != nil {
+		t.Errorf("failed %v", err)
+	}
+
+	user.Name = "after"
+	err := qb.Update("users").SetModel(user, "Name").Where("id = $2", user.ID).Save(ctx)
+	assert.NoError(t, err)
+
+	updated := &User{}
+	err = qb.Select("id", "name", "email").From("users").Where("id = $1", user.ID).Scan(ctx, updated)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "after", updated.Name)
+		assert.Equal(t, "before@gmail.com", updated.Email)
+	}
+}
+
+func TestQueryBuilderInsertModelMissingRows(t *testing.T) {
+	r, conn := newRecorder()
+	r.insertIDs = []int64{2}
+	r.queue([]string{"id"}, []any{int64(1)})
+
+	roles := []Role{{Name: "auditor"}, {Name: "owner"}}
+	err := orm.NewQueryBuilder(conn, &orm.SQLite{}, nil).InsertInto("roles").Model(&roles).Save(context.Background())
+	assert.EqualError(t, err, "save: 1 rows returned for 2 models")
+}
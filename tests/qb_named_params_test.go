@@ -0,0 +1,59 @@
+package tests_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryBuilderRawNamedParams(t *testing.T) {
+	ctx := context.Background()
+	u, err := createUser(ctx, qb)
+	if err != nil {
+		t.Errorf("failed %v", err)
+	}
+
+	var users []User
+	err = qb.
+		Raw("SELECT id, name, email FROM users WHERE id = :id AND (name = :name OR email = :name)",
+			map[string]any{"id": u.ID, "name": u.Name}).
+		Scan(ctx, &users)
+	if assert.NoError(t, err) && assert.Len(t, users, 1) {
+		assert.Equal(t, u.ID, users[0].ID)
+		assert.Equal(t, u.Name, users[0].Name)
+	}
+
+	var names []string
+	err = qb.
+		Raw("SELECT name FROM users WHERE id = :id AND id::text = :text", map[string]any{"id": u.ID, "text": "x"}).
+		Scan(ctx, &names)
+	if assert.NoError(t, err) {
+		assert.Empty(t, names)
+	}
+
+	_, err = qb.Raw("SELECT * FROM users WHERE id = :id", map[string]any{}).Exec(ctx)
+	assert.EqualError(t, err, "missing value for named parameter id")
+}
+
+func TestQueryBuilderWhereNamedParams(t *testing.T) {
+	ctx := context.Background()
+	u, err := createUser(ctx, qb)
+	if err != nil {
+		t.Errorf("failed %v", err)
+	}
+
+	// createUser only returns the id and the name
+	u.Email = "patrick@gail.com"
+
+	var ids []int64
+	err = qb.
+		Select("id").
+		From("users").
+		Where("name = ?", u.Name).
+		Where("id = :id AND email = :email", u).
+		Scan(ctx, &ids)
+	if assert.NoError(t, err) {
+		assert.Equal(t, []int64{u.ID}, ids)
+	}
+}
@@ -0,0 +1,79 @@
+package tests_test
+
+import (
+	"context"
+	"testing"
+
+	orm "github.com/patrickkabwe/goorm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryBuilderPaginate(t *testing.T) {
+	ctx := context.Background()
+	for i := 0; i < 5; i++ {
+		if _, err := createUser(ctx, qb); err != nil {
+			t.Errorf("failed %v", err)
+		}
+	}
+
+	page, err := orm.Paginate[User](ctx, qb.Select("id", "name", "email").From("users").OrderBy("id"), 1, 2)
+	assert.NoError(t, err)
+	assert.Len(t, page.Items, 2)
+	assert.GreaterOrEqual(t, page.Total, int64(5))
+	assert.Equal(t, int((page.Total+1)/2), page.TotalPages)
+}
+
+func TestQueryBuilderCursorPaginate(t *testing.T) {
+	ctx := context.Background()
+	for i := 0; i < 5; i++ {
+		if _, err := createUser(ctx, qb); err != nil {
+			t.Errorf("failed %v", err)
+		}
+	}
+
+	seen := make(map[int64]bool)
+	after := ""
+	for {
+		page, err := orm.CursorPaginate[User](ctx, qb.Select("id", "name", "email").From("users"), after, 2, "name DESC")
+		if !assert.NoError(t, err) {
+			return
+		}
+		for _, user := range page.Items {
+			assert.False(t, seen[user.ID])
+			seen[user.ID] = true
+		}
+		if !page.HasMore {
+			break
+		}
+		after = page.NextCursor
+	}
+	assert.GreaterOrEqual(t, len(seen), 5)
+}
+
+func TestQueryBuilderPaginateClauses(t *testing.T) {
+	ctx := context.Background()
+	u, err := createUser(ctx, qb)
+	if err != nil {
+		t.Errorf("failed %v", err)
+	}
+	for _, body := range []string{"first", "second"} {
+		if _, err := createPost(ctx, qb, u.ID, body); err != nil {
+			t.Errorf("failed %v", err)
+		}
+	}
+
+	// LIMIT and OFFSET follow GROUP BY and ORDER BY
+	page, err := orm.Paginate[int64](ctx, qb.Select("user_id").From("posts").GroupBy("user_id").OrderBy("user_id"), 1, 10)
+	if assert.NoError(t, err) {
+		assert.Contains(t, page.Items, u.ID)
+	}
+
+	_, err = orm.Paginate[User](ctx, qb.Select("id", "name").From("users").Limit(5), 1, 10)
+	assert.EqualError(t, err, "paginate: cannot page a query with LIMIT")
+
+	_, err = orm.Paginate[User](ctx, qb.Select("id", "name").From("users").ForUpdate(), 1, 10)
+	assert.EqualError(t, err, "paginate: cannot page a query with a locking clause")
+
+	_, err = orm.CursorPaginate[User](ctx, qb.Select("id", "name").From("users").GroupBy("id", "name"), "", 10)
+	assert.EqualError(t, err, "paginate: cannot page a query with GROUP BY")
+}
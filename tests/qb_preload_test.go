@@ -0,0 +1,80 @@
+package tests_test
+
+import (
+	"context"
+	"testing"
+
+	orm "github.com/patrickkabwe/goorm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryBuilderPreload(t *testing.T) {
+	ctx := context.Background()
+	u, err := createUser(ctx, qb)
+	if err != nil {
+		t.Errorf("failed %v", err)
+	}
+
+	err = createProfile(ctx, qb, u.ID)
+	if err != nil {
+		t.Errorf("failed %v", err)
+	}
+
+	post, err := createPost(ctx, qb, u.ID, "preloaded")
+	if err != nil {
+		t.Errorf("failed %v", err)
+	}
+
+	for _, comment := range []string{"approved", "spam"} {
+		if err := createComment(ctx, qb, post.ID, comment); err != nil {
+			t.Errorf("failed %v", err)
+		}
+	}
+
+	users := []User{}
+	err = qb.
+		Select().
+		From("users").
+		Where("users.id = $1", u.ID).
+		Preload("Profile").
+		Preload("Posts.Comments", "comments.comment = $1", "approved").
+		Scan(ctx, &users)
+
+	if assert.NoError(t, err) && assert.Len(t, users, 1) {
+		if assert.NotNil(t, users[0].Profile) {
+			assert.Equal(t, u.ID, users[0].Profile.UserID)
+		}
+		if assert.Len(t, users[0].Posts, 1) && assert.Len(t, users[0].Posts[0].Comments, 1) {
+			assert.Equal(t, "approved", users[0].Posts[0].Comments[0].Comment)
+		}
+	}
+}
+
+func TestQueryBuilderPreloadSplitsKeys(t *testing.T) {
+	r, conn := newRecorder()
+
+	// SQLite before 3.32 binds at most 999 parameters
+	var users [][]any
+	for id := int64(1); id <= 1000; id++ {
+		users = append(users, []any{id, "john", "john@mail.com"})
+	}
+	r.queue([]string{"id", "name", "email"}, users...)
+	r.queue([]string{"id", "body", "user_id"}, []any{int64(1), "first", int64(1)})
+	r.queue([]string{"id", "body", "user_id"}, []any{int64(2), "last", int64(1000)})
+
+	scanned := []User{}
+	err := orm.NewQueryBuilder(conn, &orm.SQLite{Version: "3.20"}, nil).
+		Select("id", "name", "email").
+		From("users").
+		Preload("Posts").
+		Scan(context.Background(), &scanned)
+
+	if assert.NoError(t, err) && assert.Len(t, scanned, 1000) && assert.Len(t, r.statements, 3) {
+		assert.Len(t, r.args[1], 999)
+		assert.Equal(t, []any{int64(1000)}, r.args[2])
+		if assert.Len(t, scanned[0].Posts, 1) && assert.Len(t, scanned[999].Posts, 1) {
+			assert.Equal(t, "first", scanned[0].Posts[0].Body)
+			assert.Equal(t, "last", scanned[999].Posts[0].Body)
+		}
+	}
+}
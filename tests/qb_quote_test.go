@@ -0,0 +1,68 @@
+package tests_test
+
+import (
+	"context"
+	"testing"
+
+	orm "github.com/patrickkabwe/goorm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryBuilderQuotesIdentifiers(t *testing.T) {
+	ctx := context.Background()
+	u, err := createUser(ctx, qb)
+	if err != nil {
+		t.Errorf("failed %v", err)
+	}
+
+	var users []User
+	err = qb.
+		Select("u.id", "users.Name").
+		From("public.users u").
+		Where("users.id = ?", u.ID).
+		OrderBy("Name DESC").
+		Scan(ctx, &users)
+	if assert.NoError(t, err) && assert.Len(t, users, 1) {
+		assert.Equal(t, u.Name, users[0].Name)
+	}
+
+	user := &User{}
+	err = qb.
+		Update("users").
+		Set("Name", "quoted").
+		Where("id = ?", u.ID).
+		Returning(ctx, user, "name")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "quoted", user.Name)
+	}
+}
+
+func TestQueryBuilderQuoteSQL(t *testing.T) {
+	sql := orm.NewQueryBuilder(nil, &orm.PostgreSQL{}, nil).
+		Select("order", "e.group AS kind").
+		From("events e").
+		OrderBy("order DESC").
+		GetSql()
+	assert.Equal(t, `SELECT "e"."order", "e"."group" AS "kind" FROM "events" "e" ORDER BY "order" DESC;`, sql)
+
+	sql = orm.NewQueryBuilder(nil, &orm.MYSQL{}, nil).
+		InsertInto("analytics.events").
+		Columns("user", "order").
+		Values(1, 2).
+		GetSql()
+	assert.Equal(t, "INSERT INTO `analytics`.`events`(`user`, `order`) VALUES (?, ?);", sql)
+
+	sql = orm.NewQueryBuilder(nil, &orm.PostgreSQL{}, nil).
+		SetQuoteMode(orm.QuotePreserveCase).
+		Select("createdAt").
+		From("Events").
+		GetSql()
+	assert.Equal(t, `SELECT "Events"."createdAt" FROM "Events";`, sql)
+
+	sql = orm.NewQueryBuilder(nil, &orm.PostgreSQL{}, nil).
+		SetQuoteMode(orm.QuoteNone).
+		Select("id").
+		From("events").
+		GetSql()
+	assert.Equal(t, "SELECT events.id FROM events;", sql)
+}
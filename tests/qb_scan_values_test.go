@@ -0,0 +1,67 @@
+package tests_test
+
+import (
+	"context"
+	"testing"
+
+	orm "github.com/patrickkabwe/goorm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryBuilderScanValues(t *testing.T) {
+	ctx := context.Background()
+	u, err := createUser(ctx, qb)
+	if err != nil {
+		t.Errorf("failed %v", err)
+	}
+
+	var count int64
+	err = qb.Select("COUNT(*)").From("users").Scan(ctx, &count)
+	assert.NoError(t, err)
+	assert.Greater(t, count, int64(0))
+
+	var names []string
+	err = qb.Select("name").From("users").Where("users.id = $1", u.ID).Scan(ctx, &names)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{u.Name}, names)
+
+	var report []map[string]any
+	err = qb.Select("id", "name").From("users").Where("users.id = $1", u.ID).Scan(ctx, &report)
+	assert.NoError(t, err)
+	if assert.Len(t, report, 1) {
+		assert.Equal(t, u.Name, report[0]["name"])
+	}
+
+	type row struct {
+		ID   int64
+		Name string
+	}
+	var rows []row
+	err = qb.Select("id", "name").From("users").Where("users.id = $1", u.ID).Scan(ctx, &rows)
+	assert.NoError(t, err)
+	assert.Equal(t, []row{{ID: u.ID, Name: u.Name}}, rows)
+
+	err = qb.Select("id", "name").From("users").Scan(ctx, &count)
+	assert.Error(t, err)
+}
+
+func TestQueryBuilderScanConversionErrors(t *testing.T) {
+	ctx := context.Background()
+
+	var count int64
+	err := qb.Raw("SELECT 1.5::float8").Scan(ctx, &count)
+	var conversion *orm.ConversionError
+	assert.ErrorAs(t, err, &conversion)
+
+	var small int8
+	err = qb.Raw("SELECT 300").Scan(ctx, &small)
+	assert.ErrorAs(t, err, &conversion)
+
+	var flag bool
+	err = qb.Raw("SELECT 1.0::float8").Scan(ctx, &flag)
+	assert.ErrorAs(t, err, &conversion)
+
+	err = qb.Raw("SELECT 2.0::float8").Scan(ctx, &count)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+}
@@ -0,0 +1,90 @@
+package tests_test
+
+import (
+	"context"
+	"testing"
+
+	orm "github.com/patrickkabwe/goorm"
+	"github.com/stretchr/testify/assert"
+)
+
+type userFilter struct {
+	Name  string
+	Email string
+}
+
+func namedPatrick(q *orm.QueryBuilder) {
+	q.Where("name = ?", "patrick")
+}
+
+func idAtLeast(id int64) orm.Scope {
+	return func(q *orm.QueryBuilder) {
+		q.Where("id >= ?", id)
+	}
+}
+
+func TestQueryBuilderScopes(t *testing.T) {
+	ctx := context.Background()
+	u, err := createUser(ctx, qb)
+	if err != nil {
+		t.Errorf("failed %v", err)
+	}
+
+	var ids []int64
+	err = qb.
+		Select("id").
+		From("users").
+		Scopes(namedPatrick, idAtLeast(u.ID)).
+		Where("id = ? OR id = ?", u.ID, -1).
+		Scan(ctx, &ids)
+	if assert.NoError(t, err) {
+		assert.Equal(t, []int64{u.ID}, ids)
+	}
+}
+
+func TestQueryBuilderWhen(t *testing.T) {
+	ctx := context.Background()
+	u, err := createUser(ctx, qb)
+	if err != nil {
+		t.Errorf("failed %v", err)
+	}
+
+	search := func(filter userFilter) *orm.QueryBuilder {
+		return qb.
+			Select("id").
+			From("users").
+			When(filter.Name != "", func(q *orm.QueryBuilder) { q.Where("name = ?", filter.Name) }).
+			When(filter.Email != "", func(q *orm.QueryBuilder) { q.Where("email = ?", filter.Email) }).
+			Where("id = ?", u.ID)
+	}
+
+	var ids []int64
+	err = search(userFilter{Email: "patrick@gail.com"}).Scan(ctx, &ids)
+	if assert.NoError(t, err) {
+		assert.Equal(t, []int64{u.ID}, ids)
+	}
+
+	ids = nil
+	err = search(userFilter{Name: "someone else"}).Scan(ctx, &ids)
+	if assert.NoError(t, err) {
+		assert.Empty(t, ids)
+	}
+}
+
+func TestQueryBuilderWhereJoinsConditions(t *testing.T) {
+	sql := orm.NewQueryBuilder(nil, &orm.PostgreSQL{}, nil).
+		Select("id").
+		From("users").
+		Where("name = ? OR email = ?", "a", "b").
+		Where("id > ?", 1).
+		GetSql()
+	assert.Equal(t, `SELECT "users"."id" FROM "users" WHERE ("users"."name" = $1 OR "users"."email" = $2) AND "users"."id" > $3;`, sql)
+
+	sql = orm.NewQueryBuilder(nil, &orm.MYSQL{}, nil).
+		Select("id").
+		From("users").
+		And("name = ?", "a").
+		Not("id = ?", 1).
+		GetSql()
+	assert.Equal(t, "SELECT `users`.`id` FROM `users` WHERE `users`.`name` = ? AND NOT `users`.`id` = ?;", sql)
+}
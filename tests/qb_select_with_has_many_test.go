@@ -0,0 +1,140 @@
+package tests_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryBuilderSelectWithHasManyJoin(t *testing.T) {
+	ctx := context.Background()
+	u, err := createUser(ctx, qb)
+	if err != nil {
+		t.Errorf("failed %v", err)
+	}
+
+	for _, body := range []string{"first", "second"} {
+		if _, err := createPost(ctx, qb, u.ID, body); err != nil {
+			t.Errorf("failed %v", err)
+		}
+	}
+
+	users := []User{}
+	err = qb.
+		Select("id", "name", "email", "posts.id as post_id", "posts.body as post_body").
+		From("users").
+		LeftJoin("posts", "users.id = posts.user_id").
+		Where("users.id = $1", u.ID).
+		OrderBy("posts.id").
+		Scan(ctx, &users)
+
+	if assert.NoError(t, err) && assert.Len(t, users, 1) {
+		assert.Equal(t, u.ID, users[0].ID)
+		if assert.Len(t, users[0].Posts, 2) {
+			assert.Equal(t, "first", users[0].Posts[0].Body)
+			assert.Equal(t, "second", users[0].Posts[1].Body)
+		}
+	}
+}
+
+func TestQueryBuilderSelectWithJoinWithoutHasMany(t *testing.T) {
+	ctx := context.Background()
+	u, err := createUser(ctx, qb)
+	if err != nil {
+		t.Errorf("failed %v", err)
+	}
+
+	err = createProfile(ctx, qb, u.ID)
+	if err != nil {
+		t.Errorf("failed %v", err)
+	}
+
+	users := []User{}
+	err = qb.
+		Select("id", "name", "email", "profiles.id as profile_id", "profiles.user_id").
+		From("users").
+		LeftJoin("profiles", "users.id = profiles.user_id").
+		Where("users.id = $1", u.ID).
+		Scan(ctx, &users)
+
+	if assert.NoError(t, err) && assert.Len(t, users, 1) {
+		assert.Empty(t, users[0].Posts)
+		if assert.NotNil(t, users[0].Profile) {
+			assert.Equal(t, u.ID, users[0].Profile.UserID)
+		}
+	}
+}
+
+func TestQueryBuilderSelectWithNestedHasManyJoin(t *testing.T) {
+	ctx := context.Background()
+	u, err := createUser(ctx, qb)
+	if err != nil {
+		t.Errorf("failed %v", err)
+	}
+
+	comments := map[string][]string{"first": {"a", "b"}, "second": {"c"}}
+	for _, body := range []string{"first", "second"} {
+		post, err := createPost(ctx, qb, u.ID, body)
+		if err != nil {
+			t.Errorf("failed %v", err)
+		}
+		for _, comment := range comments[body] {
+			if err := createComment(ctx, qb, post.ID, comment); err != nil {
+				t.Errorf("failed %v", err)
+			}
+		}
+	}
+
+	users := []User{}
+	err = qb.
+		Select("id", "name", "email",
+			"posts.id as post_id", "posts.body as post_body",
+			"comments.id as comment_id", "comments.comment as comment_comment").
+		From("users").
+		LeftJoin("posts", "users.id = posts.user_id").
+		LeftJoin("comments", "posts.id = comments.post_id").
+		Where("users.id = $1", u.ID).
+		OrderBy("posts.id", "comments.id").
+		Scan(ctx, &users)
+
+	if assert.NoError(t, err) && assert.Len(t, users, 1) && assert.Len(t, users[0].Posts, 2) {
+		first, second := users[0].Posts[0], users[0].Posts[1]
+		if assert.Len(t, first.Comments, 2) {
+			assert.Equal(t, "a", first.Comments[0].Comment)
+			assert.Equal(t, "b", first.Comments[1].Comment)
+		}
+		if assert.Len(t, second.Comments, 1) {
+			assert.Equal(t, "c", second.Comments[0].Comment)
+		}
+	}
+}
+
+func TestQueryBuilderSelectWithHasManyJoinWithoutKey(t *testing.T) {
+	ctx := context.Background()
+	u, err := createUser(ctx, qb)
+	if err != nil {
+		t.Errorf("failed %v", err)
+	}
+	for _, body := range []string{"first", "second", "third"} {
+		if _, err := createPost(ctx, qb, u.ID, body); err != nil {
+			t.Errorf("failed %v", err)
+		}
+	}
+
+	// without the key of the user its rows cannot be grouped, the single
+	// user is filled from the first row
+	user := &User{}
+	err = qb.
+		Select("name", "posts.id as post_id", "posts.body as post_body").
+		From("users").
+		InnerJoin("posts", "users.id = posts.user_id").
+		Where("users.id = $1", u.ID).
+		OrderBy("posts.id").
+		Scan(ctx, user)
+
+	if assert.NoError(t, err) && assert.Len(t, user.Posts, 1) {
+		assert.Equal(t, u.Name, user.Name)
+		assert.Equal(t, "first", user.Posts[0].Body)
+	}
+}
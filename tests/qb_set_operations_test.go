@@ -0,0 +1,164 @@
+package tests_test
+
+import (
+	"context"
+	"testing"
+
+	orm "github.com/patrickkabwe/goorm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryBuilderUnion(t *testing.T) {
+	ctx := context.Background()
+	u1, err := createUser(ctx, qb)
+	if err != nil {
+		t.Errorf("failed %v", err)
+	}
+	u2, err := createUser(ctx, qb)
+	if err != nil {
+		t.Errorf("failed %v", err)
+	}
+
+	other := orm.NewQueryBuilder(db, &orm.PostgreSQL{}, nil).
+		Select("id").
+		From("users").
+		Where("id = $1 OR id = $2", u1.ID, u2.ID)
+
+	var ids []int64
+	err = qb.
+		Select("id").
+		From("users").
+		Where("id = $1", u1.ID).
+		Union(other).
+		OrderBy("id DESC").
+		Scan(ctx, &ids)
+	if assert.NoError(t, err) {
+		assert.Equal(t, []int64{u2.ID, u1.ID}, ids)
+	}
+
+	other = orm.NewQueryBuilder(db, &orm.PostgreSQL{}, nil).
+		Select("id").
+		From("users").
+		Where("id = $1", u1.ID)
+
+	ids = nil
+	err = qb.
+		Select("id").
+		From("users").
+		Where("id = $1", u1.ID).
+		UnionAll(other).
+		Limit(5).
+		Scan(ctx, &ids)
+	if assert.NoError(t, err) {
+		assert.Equal(t, []int64{u1.ID, u1.ID}, ids)
+	}
+}
+
+func TestQueryBuilderIntersectExcept(t *testing.T) {
+	ctx := context.Background()
+	u1, err := createUser(ctx, qb)
+	if err != nil {
+		t.Errorf("failed %v", err)
+	}
+	u2, err := createUser(ctx, qb)
+	if err != nil {
+		t.Errorf("failed %v", err)
+	}
+
+	other := orm.NewQueryBuilder(db, &orm.PostgreSQL{}, nil).
+		Select("id").
+		From("users").
+		Where("id = $1", u2.ID)
+
+	var ids []int64
+	err = qb.
+		Select("id").
+		From("users").
+		Where("id = $1 OR id = $2", u1.ID, u2.ID).
+		Intersect(other).
+		Scan(ctx, &ids)
+	if assert.NoError(t, err) {
+		assert.Equal(t, []int64{u2.ID}, ids)
+	}
+
+	other = orm.NewQueryBuilder(db, &orm.PostgreSQL{}, nil).
+		Select("id").
+		From("users").
+		Where("id = $1", u2.ID)
+
+	ids = nil
+	err = qb.
+		Select("id").
+		From("users").
+		Where("id = $1 OR id = $2", u1.ID, u2.ID).
+		Except(other).
+		Scan(ctx, &ids)
+	if assert.NoError(t, err) {
+		assert.Equal(t, []int64{u1.ID}, ids)
+	}
+}
+
+func TestQueryBuilderSetOperationUnsupported(t *testing.T) {
+	mysql := orm.NewQueryBuilder(nil, &orm.MYSQL{Version: "8.0.30"}, nil)
+	other := orm.NewQueryBuilder(nil, &orm.MYSQL{Version: "8.0.30"}, nil).Select("id").From("admins")
+
+	_, err := mysql.Select("id").From("users").Intersect(other).Exec(context.Background())
+	assert.EqualError(t, err, "MySQL does not support INTERSECT")
+}
+
+func TestQueryBuilderWithRecursive(t *testing.T) {
+	ctx := context.Background()
+
+	anchor := orm.NewQueryBuilder(db, &orm.PostgreSQL{}, nil).Select("1 AS n")
+	recursive := orm.NewQueryBuilder(db, &orm.PostgreSQL{}, nil).
+		Select("n + 1").
+		From("numbers").
+		Where("n < $1", 5)
+
+	var numbers []int64
+	err := qb.
+		WithRecursive("numbers(n)", anchor.UnionAll(recursive)).
+		Select("n").
+		From("numbers").
+		Scan(ctx, &numbers)
+	if assert.NoError(t, err) {
+		assert.Equal(t, []int64{1, 2, 3, 4, 5}, numbers)
+	}
+}
+
+func TestQueryBuilderWithUnionParams(t *testing.T) {
+	ctx := context.Background()
+	u, err := createUser(ctx, qb)
+	if err != nil {
+		t.Errorf("failed %v", err)
+	}
+
+	anchor := orm.NewQueryBuilder(db, &orm.PostgreSQL{}, nil).
+		Select("id").
+		From("users").
+		Where("id = $1", u.ID)
+	other := orm.NewQueryBuilder(db, &orm.PostgreSQL{}, nil).
+		Select("id").
+		From("users").
+		Where("name = $1", "nobody")
+
+	var ids []int64
+	err = qb.
+		With("selected", anchor.Union(other)).
+		Select("id").
+		From("selected").
+		Where("id > $1", 0).
+		Scan(ctx, &ids)
+	if assert.NoError(t, err) {
+		assert.Equal(t, []int64{u.ID}, ids)
+	}
+
+	sql := orm.NewQueryBuilder(nil, &orm.PostgreSQL{}, nil).
+		With("selected", orm.NewQueryBuilder(nil, &orm.PostgreSQL{}, nil).
+			Select("id").From("users").Where("id = ?", 1).
+			UnionAll(orm.NewQueryBuilder(nil, &orm.PostgreSQL{}, nil).Select("id").From("users").Where("id = ?", 2))).
+		Select("id").
+		From("selected").
+		GetSql()
+	assert.Equal(t, `WITH selected AS (SELECT "users"."id" FROM "users" WHERE "users"."id" = $1 UNION ALL SELECT "users"."id" FROM "users" WHERE "users"."id" = $2) SELECT "selected"."id" FROM "selected";`, sql)
+}
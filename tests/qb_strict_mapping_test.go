@@ -0,0 +1,39 @@
+package tests_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	orm "github.com/patrickkabwe/goorm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryBuilderStrictMapping(t *testing.T) {
+	ctx := context.Background()
+	u, err := createUser(ctx, qb)
+	if err != nil {
+		t.Errorf("failed %v", err)
+	}
+
+	user := &User{}
+	err = qb.
+		Select("id", "name", "email").
+		From("users").
+		Where("users.id = $1", u.ID).
+		Strict().
+		Scan(ctx, user)
+	assert.NoError(t, err)
+
+	err = qb.
+		Select("id", "name", "email", "1 as extra").
+		From("users").
+		Where("users.id = $1", u.ID).
+		Strict().
+		Scan(ctx, user)
+
+	var mappingErr *orm.MappingError
+	if assert.True(t, errors.As(err, &mappingErr)) {
+		assert.Equal(t, []string{"extra"}, mappingErr.Columns)
+	}
+}
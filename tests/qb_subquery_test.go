@@ -0,0 +1,176 @@
+package tests_test
+
+import (
+	"context"
+	"testing"
+
+	orm "github.com/patrickkabwe/goorm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryBuilderSelectSubQuery(t *testing.T) {
+	ctx := context.Background()
+	u, err := createUser(ctx, qb)
+	if err != nil {
+		t.Errorf("failed %v", err)
+	}
+	for _, body := range []string{"first", "second", "draft"} {
+		if _, err := createPost(ctx, qb, u.ID, body); err != nil {
+			t.Errorf("failed %v", err)
+		}
+	}
+
+	type userPosts struct {
+		ID        int64 `db:"id"`
+		PostCount int64 `db:"post_count"`
+	}
+
+	count := orm.NewQueryBuilder(db, &orm.PostgreSQL{}, nil).
+		Select("COUNT(*)").
+		From("posts p").
+		Where("p.user_id = u.id AND p.body <> $1", "draft")
+
+	var result userPosts
+	err = qb.
+		Select("id", orm.As(count, "post_count")).
+		From("users u").
+		Where("id = $1", u.ID).
+		Scan(ctx, &result)
+	if assert.NoError(t, err) {
+		assert.Equal(t, userPosts{ID: u.ID, PostCount: 2}, result)
+	}
+}
+
+func TestQueryBuilderExists(t *testing.T) {
+	ctx := context.Background()
+	withPost, err := createUser(ctx, qb)
+	if err != nil {
+		t.Errorf("failed %v", err)
+	}
+	withoutPost, err := createUser(ctx, qb)
+	if err != nil {
+		t.Errorf("failed %v", err)
+	}
+	if _, err := createPost(ctx, qb, withPost.ID, "exists"); err != nil {
+		t.Errorf("failed %v", err)
+	}
+
+	posts := func() *orm.QueryBuilder {
+		return orm.NewQueryBuilder(db, &orm.PostgreSQL{}, nil).
+			Select("1").
+			From("posts p").
+			Where("p.user_id = u.id AND p.body = $1", "exists")
+	}
+
+	var ids []int64
+	err = qb.
+		Select("id").
+		From("users u").
+		Where("id = $1 OR id = $2", withPost.ID, withoutPost.ID).
+		Exists(posts()).
+		Scan(ctx, &ids)
+	if assert.NoError(t, err) {
+		assert.Equal(t, []int64{withPost.ID}, ids)
+	}
+
+	ids = nil
+	err = qb.
+		Select("id").
+		From("users u").
+		NotExists(posts()).
+		And("id = $1", withoutPost.ID).
+		Scan(ctx, &ids)
+	if assert.NoError(t, err) {
+		assert.Equal(t, []int64{withoutPost.ID}, ids)
+	}
+}
+
+func TestQueryBuilderInSubQuery(t *testing.T) {
+	ctx := context.Background()
+	u, err := createUser(ctx, qb)
+	if err != nil {
+		t.Errorf("failed %v", err)
+	}
+	if _, err := createPost(ctx, qb, u.ID, "in subquery"); err != nil {
+		t.Errorf("failed %v", err)
+	}
+
+	authors := orm.NewQueryBuilder(db, &orm.PostgreSQL{}, nil).
+		Select("user_id").
+		From("posts").
+		Where("body = $1", "in subquery")
+
+	var names []string
+	err = qb.
+		Select("name").
+		From("users").
+		Where("id").
+		In("id", authors).
+		Scan(ctx, &names)
+	if assert.NoError(t, err) {
+		assert.Contains(t, names, u.Name)
+	}
+}
+
+func TestQueryBuilderFromSubQuery(t *testing.T) {
+	ctx := context.Background()
+	u, err := createUser(ctx, qb)
+	if err != nil {
+		t.Errorf("failed %v", err)
+	}
+
+	selected := orm.NewQueryBuilder(db, &orm.PostgreSQL{}, nil).
+		Select("id", "name").
+		From("users").
+		Where("id = $1", u.ID)
+
+	var names []string
+	err = qb.
+		Select("name").
+		From(selected, "selected").
+		Where("name = $1", u.Name).
+		Scan(ctx, &names)
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{u.Name}, names)
+	}
+}
+
+func TestQueryBuilderSubQueryPlaceholders(t *testing.T) {
+	ctx := context.Background()
+	u, err := createUser(ctx, qb)
+	if err != nil {
+		t.Errorf("failed %v", err)
+	}
+
+	type userPosts struct {
+		Name      string `db:"name"`
+		PostCount int64  `db:"post_count"`
+	}
+
+	count := orm.NewQueryBuilder(db, &orm.PostgreSQL{}, nil).
+		Select("COUNT(*)").
+		From("posts p").
+		Where("p.user_id = u.id AND p.body = $1", "x")
+
+	var result userPosts
+	err = qb.
+		Select("name", orm.As(count, "post_count")).
+		From("users u").
+		Where("name = $1", u.Name).
+		Where("id = $2", u.ID).
+		Scan(ctx, &result)
+	if assert.NoError(t, err) {
+		assert.Equal(t, userPosts{Name: u.Name}, result)
+	}
+
+	count = orm.NewQueryBuilder(nil, &orm.PostgreSQL{}, nil).
+		Select("COUNT(*)").
+		From("posts").
+		Where("body = $1", "x")
+	sql := orm.NewQueryBuilder(nil, &orm.PostgreSQL{}, nil).
+		Select(orm.As(count, "post_count")).
+		From("users").
+		Where("name = $1", "patrick").
+		GetSql()
+	assert.Equal(t, `SELECT (SELECT COUNT(*) FROM "posts" WHERE "posts"."body" = $1) AS post_count FROM "users" WHERE "users"."name" = $2;`, sql)
+}
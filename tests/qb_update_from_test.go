@@ -0,0 +1,142 @@
+package tests_test
+
+import (
+	"context"
+	"testing"
+
+	orm "github.com/patrickkabwe/goorm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryBuilderUpdateFrom(t *testing.T) {
+	ctx := context.Background()
+	u, err := createUser(ctx, qb)
+	if err != nil {
+		t.Errorf("failed %v", err)
+	}
+	post, err := createPost(ctx, qb, u.ID, "from post")
+	if err != nil {
+		t.Errorf("failed %v", err)
+	}
+
+	user := &User{}
+	err = qb.
+		Update("users").
+		From("posts p").
+		SetExpr("name = p.body").
+		Where("users.id = p.user_id AND p.id = $1", post.ID).
+		Returning(ctx, user, "id", "name")
+	if assert.NoError(t, err) {
+		assert.Equal(t, u.ID, user.ID)
+		assert.Equal(t, "from post", user.Name)
+	}
+}
+
+func TestQueryBuilderDeleteUsing(t *testing.T) {
+	ctx := context.Background()
+	u, err := createUser(ctx, qb)
+	if err != nil {
+		t.Errorf("failed %v", err)
+	}
+	post, err := createPost(ctx, qb, u.ID, "with comments")
+	if err != nil {
+		t.Errorf("failed %v", err)
+	}
+	if err := createComment(ctx, qb, post.ID, "deleted"); err != nil {
+		t.Errorf("failed %v", err)
+	}
+
+	rows, err := qb.
+		Delete("comments").
+		Using(orm.Ref("posts").As("p")).
+		Where("comments.post_id = p.id AND p.user_id = $1", u.ID).
+		Exec(ctx)
+	if assert.NoError(t, err) {
+		rows.Close()
+	}
+
+	var comments []string
+	err = qb.Select("comment").From("comments").Where("post_id = $1", post.ID).Scan(ctx, &comments)
+	if assert.NoError(t, err) {
+		assert.Empty(t, comments)
+	}
+}
+
+func TestQueryBuilderUpdateFromSQL(t *testing.T) {
+	sql := orm.NewQueryBuilder(nil, &orm.MYSQL{}, nil).
+		Update("users").
+		From("staging s").
+		SetExpr("users.name = s.name").
+		Where("users.email = s.email").
+		GetSql()
+	assert.Equal(t, "UPDATE `users` JOIN `staging` `s` SET `users`.`name` = `s`.`name` WHERE `users`.`email` = `s`.`email`;", sql)
+
+	sql = orm.NewQueryBuilder(nil, &orm.MYSQL{}, nil).
+		Delete("sessions").
+		Using("users u").
+		Where("sessions.user_id = u.id").
+		GetSql()
+	assert.Equal(t, "DELETE `sessions` FROM `sessions` JOIN `users` `u` WHERE `sessions`.`user_id` = `u`.`id`;", sql)
+
+	_, err := orm.NewQueryBuilder(nil, &orm.SQLite{}, nil).
+		Delete("sessions").
+		Using("users u").
+		Exec(context.Background())
+	assert.EqualError(t, err, "SQLite does not support DELETE with USING")
+}
+
+func TestQueryBuilderReturningFallback(t *testing.T) {
+	ctx := context.Background()
+	r, conn := newRecorder()
+	r.queue([]string{"id", "name"}, []any{int64(3), "fallback"})
+
+	user := &User{}
+	err := orm.NewQueryBuilder(conn, &orm.MYSQL{Version: "8.0"}, nil).
+		Update("users").
+		From("staging s").
+		Set("name", "fallback").
+		Where("users.id = s.user_id AND s.id = ?", 7).
+		Returning(ctx, user, "id", "name")
+	if assert.NoError(t, err) && assert.Len(t, r.statements, 2) {
+		assert.Equal(t, "SELECT `users`.`id`, `users`.`name` FROM `users` JOIN `staging` `s` WHERE `users`.`id` = `s`.`user_id` AND `s`.`id` = ?", r.statements[1])
+		assert.Equal(t, []any{int64(7)}, r.args[1])
+		assert.Equal(t, &User{ID: 3, Name: "fallback"}, user)
+	}
+}
+
+func TestQueryBuilderUpdateFromSourceParams(t *testing.T) {
+	ctx := context.Background()
+	for _, dialect := range []orm.Dialect{&orm.MYSQL{}, &orm.SQLite{}} {
+		r, conn := newRecorder()
+		q := orm.NewQueryBuilder(conn, dialect, nil)
+		staged := orm.NewQueryBuilder(nil, dialect, nil).Select("user_id", "name").From("staging").Where("batch = ?", 3)
+
+		// the source is rendered before SET on MySQL and after it on SQLite
+		rows, err := q.
+			Update("users").
+			From(staged, "s").
+			Set("email", "moved@mail.com").
+			Where("users.id = s.user_id AND s.name = ?", "john").
+			Exec(ctx)
+		if assert.NoError(t, err) && assert.Len(t, r.args, 1) {
+			rows.Close()
+			if _, ok := dialect.(*orm.MYSQL); ok {
+				assert.Equal(t, []any{int64(3), "moved@mail.com", "john"}, r.args[0])
+			} else {
+				assert.Equal(t, []any{"moved@mail.com", int64(3), "john"}, r.args[0])
+			}
+		}
+	}
+
+	r, conn := newRecorder()
+	rows, err := orm.NewQueryBuilder(conn, &orm.MYSQL{}, nil).
+		Delete("sessions s").
+		Using("users u").
+		Where("s.user_id = u.id AND u.active = ?", false).
+		Exec(ctx)
+	if assert.NoError(t, err) && assert.Len(t, r.statements, 1) {
+		rows.Close()
+		assert.Equal(t, "DELETE `s` FROM `sessions` `s` JOIN `users` `u` WHERE `s`.`user_id` = `u`.`id` AND `u`.`active` = ?;", r.statements[0])
+		assert.Equal(t, []any{false}, r.args[0])
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"testing"
 
+	orm "github.com/patrickkabwe/goorm"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -19,11 +20,7 @@ func TestQueryBuilderUpdateClause(t *testing.T) {
 	user := &User{}
 	err = qb.
 		Update("users").
-		Set(
-			map[string]string{
-				"name": name,
-			},
-		).
+		Set("name", name).
 		Where(fmt.Sprintf("id = %s", qb.Dialect.GetPlaceholder(2)), u.ID).
 		Returning(ctx, user, "name")
 
@@ -31,3 +28,24 @@ func TestQueryBuilderUpdateClause(t *testing.T) {
 		assert.Equal(t, user.Name, name)
 	}
 }
+
+func TestQueryBuilderUpdateSetExpressions(t *testing.T) {
+	ctx := context.Background()
+	u, err := createUser(ctx, qb)
+	if err != nil {
+		t.Errorf("failed %v", err)
+	}
+
+	user := &User{}
+	err = qb.
+		Update("users").
+		SetExpr("name = name || '!'").
+		Set("email", orm.NewQueryBuilder(db, &orm.PostgreSQL{}, nil).Select("'sub@gmail.com'")).
+		Where("id = $1", u.ID).
+		Returning(ctx, user, "name", "email")
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, u.Name+"!", user.Name)
+		assert.Equal(t, "sub@gmail.com", user.Email)
+	}
+}
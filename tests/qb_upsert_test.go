@@ -0,0 +1,80 @@
+package tests_test
+
+import (
+	"context"
+	"testing"
+
+	orm "github.com/patrickkabwe/goorm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryBuilderUpsert(t *testing.T) {
+	ctx := context.Background()
+	role, err := createRole(ctx, qb, "viewer")
+	if err != nil {
+		t.Errorf("failed %v", err)
+	}
+
+	updated := &Role{}
+	err = qb.
+		InsertInto("roles").
+		Columns("id", "name").
+		Values(role.ID, "editor").
+		OnConflict("id").
+		DoUpdateSet("name").
+		Returning(ctx, updated, "id", "name")
+	if assert.NoError(t, err) {
+		assert.Equal(t, role.ID, updated.ID)
+		assert.Equal(t, "editor", updated.Name)
+	}
+
+	u, err := createUser(ctx, qb)
+	if err != nil {
+		t.Errorf("failed %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		rows, err := qb.
+			InsertInto("user_roles").
+			Columns("user_id", "role_id").
+			Values(u.ID, role.ID).
+			OnConflict().
+			DoNothing().
+			Exec(ctx)
+		if assert.NoError(t, err) {
+			rows.Close()
+		}
+	}
+
+	var count int64
+	err = qb.Select("COUNT(*)").From("user_roles").Where("user_id = $1", u.ID).Scan(ctx, &count)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestQueryBuilderUpsertSQL(t *testing.T) {
+	sql := orm.NewQueryBuilder(nil, &orm.MYSQL{Version: "8.0.36"}, nil).
+		InsertInto("users").
+		Columns("name", "email").
+		Values("John", "john@mail.com").
+		OnConflict("email").
+		DoUpdateSet("name").
+		GetSql()
+	assert.Equal(t, "INSERT INTO `users`(`name`, `email`) VALUES (?, ?) AS new ON DUPLICATE KEY UPDATE `name` = new.`name`;", sql)
+
+	_, err := orm.NewQueryBuilder(nil, &orm.SQLite{Version: "3.22"}, nil).
+		InsertInto("users").
+		Columns("name").
+		Values("John").
+		OnConflict("name").
+		DoNothing().
+		Exec(context.Background())
+	assert.EqualError(t, err, "SQLite does not support upserts before 3.24")
+
+	_, err = orm.NewQueryBuilder(nil, &orm.MYSQL{}, nil).
+		InsertInto("users").
+		Values("John", "john@mail.com").
+		OnConflict().
+		DoNothing().
+		Exec(context.Background())
+	assert.EqualError(t, err, "MySQL requires the inserted columns to do nothing on conflict")
+}
@@ -0,0 +1,85 @@
+package tests_test
+
+import (
+	"context"
+	"testing"
+
+	orm "github.com/patrickkabwe/goorm"
+	"github.com/stretchr/testify/assert"
+)
+
+type rankedPost struct {
+	ID       int64  `db:"id"`
+	Body     string `db:"body"`
+	Position int64  `db:"position"`
+	Previous string `db:"previous"`
+	Total    int64  `db:"total"`
+}
+
+func TestQueryBuilderWindowFunctions(t *testing.T) {
+	ctx := context.Background()
+	u, err := createUser(ctx, qb)
+	if err != nil {
+		t.Errorf("failed %v", err)
+	}
+	for _, body := range []string{"a", "b", "c"} {
+		if _, err := createPost(ctx, qb, u.ID, body); err != nil {
+			t.Errorf("failed %v", err)
+		}
+	}
+
+	var posts []rankedPost
+	err = qb.
+		Select(
+			"id",
+			"body",
+			orm.RowNumber().Over(orm.PartitionBy("user_id"), orm.OrderBy("id")).As("position"),
+			orm.Lag("body", 1, "none").Over(orm.PartitionBy("user_id"), orm.OrderBy("id")).As("previous"),
+			orm.Count("*").Over(
+				orm.PartitionBy("user_id"),
+				orm.OrderBy("id"),
+				orm.Frame("ROWS BETWEEN UNBOUNDED PRECEDING AND CURRENT ROW"),
+			).As("total"),
+		).
+		From("posts").
+		Where("user_id = $1", u.ID).
+		OrderBy("id").
+		Scan(ctx, &posts)
+	if assert.NoError(t, err) && assert.Len(t, posts, 3) {
+		assert.Equal(t, []int64{1, 2, 3}, []int64{posts[0].Position, posts[1].Position, posts[2].Position})
+		assert.Equal(t, []string{"none", "a", "b"}, []string{posts[0].Previous, posts[1].Previous, posts[2].Previous})
+		assert.Equal(t, []int64{1, 2, 3}, []int64{posts[0].Total, posts[1].Total, posts[2].Total})
+	}
+}
+
+func TestQueryBuilderNamedWindow(t *testing.T) {
+	ctx := context.Background()
+	u, err := createUser(ctx, qb)
+	if err != nil {
+		t.Errorf("failed %v", err)
+	}
+	for _, body := range []string{"x", "y"} {
+		if _, err := createPost(ctx, qb, u.ID, body); err != nil {
+			t.Errorf("failed %v", err)
+		}
+	}
+
+	var positions []int64
+	err = qb.
+		Select(orm.Rank().OverWindow("w").As("position")).
+		From("posts").
+		Where("user_id = $1", u.ID).
+		Window("w", orm.PartitionBy("user_id"), orm.OrderBy("id DESC")).
+		OrderBy("position").
+		Scan(ctx, &positions)
+	if assert.NoError(t, err) {
+		assert.Equal(t, []int64{1, 2}, positions)
+	}
+}
+
+func TestQueryBuilderWindowUnsupported(t *testing.T) {
+	mysql := orm.NewQueryBuilder(nil, &orm.MYSQL{Version: "5.7"}, nil)
+
+	_, err := mysql.Select("id", orm.RowNumber().Over()).From("posts").Exec(context.Background())
+	assert.EqualError(t, err, "MySQL does not support window functions")
+}
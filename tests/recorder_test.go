@@ -0,0 +1,118 @@
+package tests_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"sync"
+)
+
+// recorder is a database/sql driver recording the statements it runs, it
+// tests the SQL sent for dialects without a database in the test setup.
+// Queries return the queued rows in order and statements the queued last
+// insert ids, commits and rollbacks are counted.
+type recorder struct {
+	mu         sync.Mutex
+	statements []string
+	args       [][]any
+	rows       [][][]any
+	columns    [][]string
+	insertIDs  []int64
+	commits    int
+	rollbacks  int
+}
+
+func newRecorder() (*recorder, *sql.DB) {
+	r := &recorder{}
+	return r, sql.OpenDB(r)
+}
+
+// queue adds the rows returned by the next query
+func (r *recorder) queue(columns []string, rows ...[]any) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.columns = append(r.columns, columns)
+	r.rows = append(r.rows, rows)
+}
+
+func (r *recorder) record(query string, args []driver.NamedValue) {
+	values := make([]any, len(args))
+	for i, arg := range args {
+		values[i] = arg.Value
+	}
+	r.statements = append(r.statements, query)
+	r.args = append(r.args, values)
+}
+
+func (r *recorder) Connect(context.Context) (driver.Conn, error) { return &recorderConn{r}, nil }
+func (r *recorder) Driver() driver.Driver                        { return nil }
+
+type recorderConn struct{ r *recorder }
+
+func (c *recorderConn) Prepare(string) (driver.Stmt, error) { return nil, driver.ErrSkip }
+func (c *recorderConn) Close() error                        { return nil }
+func (c *recorderConn) Begin() (driver.Tx, error)           { return c, nil }
+
+func (c *recorderConn) Commit() error {
+	c.r.mu.Lock()
+	defer c.r.mu.Unlock()
+	c.r.commits++
+	return nil
+}
+
+func (c *recorderConn) Rollback() error {
+	c.r.mu.Lock()
+	defer c.r.mu.Unlock()
+	c.r.rollbacks++
+	return nil
+}
+
+func (c *recorderConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.r.mu.Lock()
+	defer c.r.mu.Unlock()
+	c.r.record(query, args)
+
+	var id int64
+	if len(c.r.insertIDs) > 0 {
+		id, c.r.insertIDs = c.r.insertIDs[0], c.r.insertIDs[1:]
+	}
+	return recorderResult(id), nil
+}
+
+func (c *recorderConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.r.mu.Lock()
+	defer c.r.mu.Unlock()
+	c.r.record(query, args)
+
+	rows := &recorderRows{}
+	if len(c.r.columns) > 0 {
+		rows.columns, rows.rows = c.r.columns[0], c.r.rows[0]
+		c.r.columns, c.r.rows = c.r.columns[1:], c.r.rows[1:]
+	}
+	return rows, nil
+}
+
+type recorderResult int64
+
+func (r recorderResult) LastInsertId() (int64, error) { return int64(r), nil }
+func (r recorderResult) RowsAffected() (int64, error) { return 1, nil }
+
+type recorderRows struct {
+	columns []string
+	rows    [][]any
+}
+
+func (r *recorderRows) Columns() []string { return r.columns }
+func (r *recorderRows) Close() error      { return nil }
+
+func (r *recorderRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	for i, value := range r.rows[0] {
+		dest[i] = value
+	}
+	r.rows = r.rows[1:]
+	return nil
+}
@@ -18,6 +18,8 @@ type User struct {
 	Name    string   `db:"name"`
 	Email   string   `db:"email"`
 	Profile *Profile `db:"profiles"`
+	Posts   []Post   `db:"posts"`
+	Roles   []Role   `goorm:"many2many:user_roles"`
 }
 
 type Profile struct {
@@ -26,6 +28,24 @@ type Profile struct {
 	UserID int64  `db:"user_id"`
 }
 
+type Post struct {
+	ID       int64     `db:"id"`
+	Body     string    `db:"body"`
+	UserID   int64     `db:"user_id"`
+	Comments []Comment `db:"comments"`
+}
+
+type Role struct {
+	ID   int64  `db:"id"`
+	Name string `db:"name"`
+}
+
+type Comment struct {
+	ID      int64  `db:"id"`
+	Comment string `db:"comment"`
+	PostID  int64  `db:"post_id"`
+}
+
 func TestMain(m *testing.M) {
 	// Setup database
 	var err error
@@ -95,6 +115,17 @@ func createTables(db *sql.DB) error {
 			constraint fk_user_id foreign key(user_id) references users(id) on delete cascade,
 			constraint fk_post_id foreign key(post_id) references posts(id) on delete cascade
 		);
+
+		create table if not exists roles(
+			id serial primary key,
+			name varchar(255) not null
+		);
+
+		create table if not exists user_roles(
+			user_id integer references users(id) on delete cascade,
+			role_id integer references roles(id) on delete cascade,
+			primary key(user_id, role_id)
+		);
 	`
 	_, err := db.Exec(query)
 	if err != nil {
@@ -125,3 +156,36 @@ func createProfile(ctx context.Context, builder *orm.QueryBuilder, userID int64)
 
 	return err
 }
+
+func createPost(ctx context.Context, builder *orm.QueryBuilder, userID int64, body string) (*Post, error) {
+	post := &Post{}
+	err := builder.
+		InsertInto("posts").
+		Columns("user_id", "body").
+		Values(userID, body).
+		Returning(ctx, post, "id", "user_id", "body")
+
+	return post, err
+}
+
+func createComment(ctx context.Context, builder *orm.QueryBuilder, postID int64, comment string) error {
+	c := &Comment{}
+	err := builder.
+		InsertInto("comments").
+		Columns("post_id", "comment").
+		Values(postID, comment).
+		Returning(ctx, c, "id")
+
+	return err
+}
+
+func createRole(ctx context.Context, builder *orm.QueryBuilder, name string) (*Role, error) {
+	role := &Role{}
+	err := builder.
+		InsertInto("roles").
+		Columns("name").
+		Values(name).
+		Returning(ctx, role, "id", "name")
+
+	return role, err
+}
@@ -0,0 +1,69 @@
+package goorm
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// querier is implemented by *sql.DB and *sql.Tx
+type querier interface {
+	execer
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// conn returns the transaction of the builder, or its database outside of
+// a transaction
+func (q *QueryBuilder) conn() querier {
+	if q.tx != nil {
+		return q.tx
+	}
+	return q.db
+}
+
+// moveTo moves the query being built to tx, the builder of a transaction,
+// and resets the builder
+func (q *QueryBuilder) moveTo(tx *QueryBuilder) {
+	query := q.query.String()
+	conn := tx.tx
+	*tx = *q
+	tx.tx = conn
+	tx.query = strings.Builder{}
+	tx.query.WriteString(query)
+	q.Reset()
+}
+
+// Transaction runs fn in a transaction, the queries of the QueryBuilder
+// passed to fn are part of it. The transaction is committed when fn returns
+// nil and rolled back otherwise.
+// e.g.
+//
+//	err := qb.Transaction(ctx, func(tx *goorm.QueryBuilder) error {
+//		return tx.InsertInto("users").Columns("name").Values("John").Returning(ctx, &user, "id")
+//	})
+func (q *QueryBuilder) Transaction(ctx context.Context, fn func(tx *QueryBuilder) error) error {
+	if q.tx != nil {
+		// already in a transaction
+		return fn(q)
+	}
+
+	tx, err := q.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	txq := q.fork()
+	txq.tx = tx
+	if err := fn(txq); err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			return fmt.Errorf("failed to rollback transaction: %w", rbErr)
+		}
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
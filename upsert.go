@@ -0,0 +1,159 @@
+package goorm
+
+import (
+	"fmt"
+	"strings"
+)
+
+// conflictClause is the ON CONFLICT clause of an insert, it is rendered
+// once the query is complete since MySQL needs the inserted columns
+type conflictClause struct {
+	target    []string
+	doNothing bool
+	set       []string
+}
+
+// OnConflict turns the insert into an upsert on the unique columns, which
+// can be omitted with DoNothing e.g.
+//
+//	qb.InsertInto("users").Columns("name", "email").Values("John", "john@mail.com").
+//		OnConflict("email").DoUpdateSet("name")
+//
+// MySQL does not name the conflicting columns: the columns are ignored
+// there and the upsert applies to a conflict on any unique index,
+// including the primary key, so the same query runs on every dialect.
+func (q *QueryBuilder) OnConflict(columns ...string) *QueryBuilder {
+	if !supportsUpsert(q.Dialect) {
+		q.err = fmt.Errorf("%s does not support upserts before 3.24", dialectName(q.Dialect))
+		return q
+	}
+	if !supportsConflictTarget(q.Dialect) {
+		columns = nil
+	}
+
+	q.conflict = &conflictClause{target: columns}
+	return q
+}
+
+// DoUpdateSet updates the columns of the conflicting row with the values
+// that were to be inserted. Columns can also be assigned an expression
+// e.g. DoUpdateSet("name", "updated_at = CURRENT_TIMESTAMP")
+func (q *QueryBuilder) DoUpdateSet(columns ...string) *QueryBuilder {
+	if q.conflict == nil {
+		if q.err == nil {
+			q.err = fmt.Errorf("DoUpdateSet requires OnConflict")
+		}
+		return q
+	}
+	if len(columns) == 0 {
+		q.err = fmt.Errorf("DoUpdateSet requires at least one column")
+		return q
+	}
+	if len(q.conflict.target) == 0 && supportsConflictTarget(q.Dialect) {
+		q.err = fmt.Errorf("%s requires the conflicting columns to update on conflict", dialectName(q.Dialect))
+		return q
+	}
+
+	q.conflict.set = append(q.conflict.set, columns...)
+	return q
+}
+
+// DoNothing keeps the conflicting row as is
+func (q *QueryBuilder) DoNothing() *QueryBuilder {
+	if q.conflict == nil {
+		if q.err == nil {
+			q.err = fmt.Errorf("DoNothing requires OnConflict")
+		}
+		return q
+	}
+
+	q.conflict.doNothing = true
+	return q
+}
+
+// conflictSQL renders the conflict clause for the dialect
+func (q *QueryBuilder) conflictSQL() string {
+	c := q.conflict
+	if !c.doNothing && len(c.set) == 0 {
+		c.doNothing = true
+	}
+
+	if q.Dialect.GetName() == Mysql {
+		return q.duplicateKeySQL()
+	}
+
+	var b strings.Builder
+	b.WriteString(" ON CONFLICT")
+	if len(c.target) > 0 {
+		target := make([]string, len(c.target))
+		for i, column := range c.target {
+			target[i] = q.quoteName(column)
+		}
+		b.WriteString(" (" + strings.Join(target, ", ") + ")")
+	}
+	if c.doNothing {
+		b.WriteString(" DO NOTHING")
+		return b.String()
+	}
+
+	b.WriteString(" DO UPDATE SET ")
+	for i, column := range c.set {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		if strings.Contains(column, "=") {
+			b.WriteString(column)
+		} else {
+			column = q.quoteName(column)
+			b.WriteString(fmt.Sprintf("%s = EXCLUDED.%s", column, column))
+		}
+	}
+	return b.String()
+}
+
+// checkConflict reports conflict clauses the dialect cannot render, MySQL
+// keeps the row on conflict by assigning an inserted column to itself
+func (q *QueryBuilder) checkConflict() error {
+	if q.conflict == nil || q.Dialect.GetName() != Mysql || len(q.conflict.set) > 0 {
+		return nil
+	}
+	if len(q.columns) == 0 {
+		return fmt.Errorf("%s requires the inserted columns to do nothing on conflict", dialectName(q.Dialect))
+	}
+	return nil
+}
+
+// duplicateKeySQL renders ON DUPLICATE KEY UPDATE, referencing the inserted
+// row with an alias on MySQL 8.0.19 and later and with VALUES(col) before
+func (q *QueryBuilder) duplicateKeySQL() string {
+	c := q.conflict
+	set := c.set
+	if c.doNothing {
+		column := q.quoteName(q.columns[0])
+		return fmt.Sprintf(" ON DUPLICATE KEY UPDATE %s = %s", column, column)
+	}
+
+	alias := supportsRowAlias(q.Dialect)
+	var b strings.Builder
+	if alias {
+		b.WriteString(" AS new")
+	}
+	b.WriteString(" ON DUPLICATE KEY UPDATE ")
+	for i, column := range set {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		if !strings.Contains(column, "=") {
+			column = q.quoteName(column)
+		}
+		switch {
+		case strings.Contains(column, "="):
+			b.WriteString(column)
+		case alias:
+			b.WriteString(fmt.Sprintf("%s = new.%s", column, column))
+		default:
+			b.WriteString(fmt.Sprintf("%s = VALUES(%s)", column, column))
+		}
+	}
+	return b.String()
+}
@@ -2,6 +2,7 @@ package goorm
 
 import (
 	"os"
+	"strconv"
 	"strings"
 )
 
@@ -67,6 +68,21 @@ func hasOperation(operations []string, operation string) bool {
 	return false
 }
 
+// dialectName returns the name of the database of the dialect for messages,
+// GetName returns the name of its driver e.g. pgx
+func dialectName(dialect Dialect) string {
+	switch dialect.(type) {
+	case *PostgreSQL:
+		return "PostgreSQL"
+	case *MYSQL:
+		return "MySQL"
+	case *SQLite:
+		return "SQLite"
+	default:
+		return string(dialect.GetName())
+	}
+}
+
 func supportsReturning(dialect Dialect) bool {
 	switch dialect.GetName() {
 	case Postgres:
@@ -76,6 +92,61 @@ func supportsReturning(dialect Dialect) bool {
 	}
 }
 
+// supportsUpsert reports whether the dialect supports ON CONFLICT or
+// ON DUPLICATE KEY UPDATE, SQLite supports upserts since 3.24
+func supportsUpsert(dialect Dialect) bool {
+	switch d := dialect.(type) {
+	case *SQLite:
+		return versionAtLeast(d.Version, "3.24")
+	default:
+		return true
+	}
+}
+
+// supportsConflictTarget reports whether the columns of the unique index
+// an upsert conflicts on can be named, MySQL updates on any unique index
+func supportsConflictTarget(dialect Dialect) bool {
+	switch dialect.GetName() {
+	case Mysql:
+		return false
+	default:
+		return true
+	}
+}
+
+// supportsRowAlias reports whether MySQL accepts an alias of the inserted
+// row, which replaces VALUES(col) since 8.0.19
+func supportsRowAlias(dialect Dialect) bool {
+	if d, ok := dialect.(*MYSQL); ok {
+		return versionAtLeast(d.Version, "8.0.19")
+	}
+	return false
+}
+
+// versionAtLeast compares dotted versions e.g. 8.0.36 and 8.0.19, an empty
+// version is the latest one
+func versionAtLeast(version string, min string) bool {
+	if version == "" {
+		return true
+	}
+
+	parts := strings.Split(version, ".")
+	minParts := strings.Split(min, ".")
+	for i, m := range minParts {
+		want, _ := strconv.Atoi(m)
+		got := 0
+		if i < len(parts) {
+			// ignore suffixes e.g. 8.0.36-log
+			digits := strings.TrimRightFunc(parts[i], func(r rune) bool { return r < '0' || r > '9' })
+			got, _ = strconv.Atoi(digits)
+		}
+		if got != want {
+			return got > want
+		}
+	}
+	return true
+}
+
 func isColumnNameInWhere(parts []string, pos int) bool {
 	if pos >= len(parts)-1 {
 		return false
@@ -99,3 +170,203 @@ func isColumnNameInWhere(parts []string, pos int) bool {
 
 	return false
 }
+
+// maxParams returns the number of parameters a statement can bind, SQLite
+// raised its limit from 999 to 32766 in 3.32
+func maxParams(dialect Dialect) int {
+	switch d := dialect.(type) {
+	case *SQLite:
+		if versionAtLeast(d.Version, "3.32") {
+			return 32766
+		}
+		return 999
+	default:
+		return 65535
+	}
+}
+
+// shiftPlaceholders adds offset to the numbered placeholders of a query
+// e.g. $1 becomes $3 with an offset of 2, quoted text is left as is
+func shiftPlaceholders(query string, offset int) string {
+	if offset == 0 || !strings.Contains(query, "$") {
+		return query
+	}
+
+	var b strings.Builder
+	var quote byte
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '$':
+			j := i + 1
+			for j < len(query) && query[j] >= '0' && query[j] <= '9' {
+				j++
+			}
+			if j > i+1 {
+				n, _ := strconv.Atoi(query[i+1 : j])
+				b.WriteString("$" + strconv.Itoa(n+offset))
+				i = j - 1
+				continue
+			}
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+// supportsCTE reports whether the dialect supports WITH queries, MySQL
+// supports them since 8.0
+func supportsCTE(dialect Dialect) bool {
+	switch d := dialect.(type) {
+	case *MYSQL:
+		return versionAtLeast(d.Version, "8.0")
+	case *SQLite:
+		return versionAtLeast(d.Version, "3.8.3")
+	default:
+		return true
+	}
+}
+
+// supportsSetOperation reports whether the dialect can combine queries with
+// the operation, MySQL supports INTERSECT and EXCEPT since 8.0.31
+func supportsSetOperation(dialect Dialect, operation string) bool {
+	switch d := dialect.(type) {
+	case *MYSQL:
+		if operation == "INTERSECT" || operation == "EXCEPT" {
+			return versionAtLeast(d.Version, "8.0.31")
+		}
+	}
+	return true
+}
+
+// isColumnName reports whether a field of a SELECT is a bare column name
+// rather than *, a qualified or aliased column, a function or a literal
+func isColumnName(field string) bool {
+	if field == "" || field == "*" ||
+		strings.Contains(field, "(") ||
+		strings.Contains(field, ".") ||
+		strings.Contains(field, " as ") ||
+		strings.Contains(field, " AS ") ||
+		strings.HasPrefix(field, "'") {
+		return false
+	}
+	_, err := strconv.ParseFloat(field, 64)
+	return err != nil
+}
+
+// supportsWindow reports whether the dialect supports window functions,
+// MySQL supports them since 8.0 and SQLite since 3.25
+func supportsWindow(dialect Dialect) bool {
+	switch d := dialect.(type) {
+	case *MYSQL:
+		return versionAtLeast(d.Version, "8.0")
+	case *SQLite:
+		return versionAtLeast(d.Version, "3.25")
+	default:
+		return true
+	}
+}
+
+// supportsLateral reports whether the dialect supports LATERAL joins,
+// MySQL supports them since 8.0.14 and SQLite not at all
+func supportsLateral(dialect Dialect) bool {
+	switch d := dialect.(type) {
+	case *MYSQL:
+		return versionAtLeast(d.Version, "8.0.14")
+	case *SQLite:
+		return false
+	default:
+		return true
+	}
+}
+
+// bindPlaceholders numbers the ? placeholders of a condition after offset
+// parameters with the placeholders of the dialect when there is one per
+// argument, so that ? operators of PostgreSQL are left as is otherwise
+func bindPlaceholders(dialect Dialect, condition string, offset int, args int) string {
+	if args == 0 || dialect == nil || strings.Count(condition, "?") < args {
+		return condition
+	}
+
+	var b strings.Builder
+	var quote byte
+	n := 0
+	for i := 0; i < len(condition); i++ {
+		c := condition[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '?':
+			n++
+			b.WriteString(dialect.GetPlaceholder(offset + n))
+			continue
+		}
+		b.WriteByte(c)
+	}
+	if n != args {
+		return condition
+	}
+	return b.String()
+}
+
+// bindPlaceholders numbers the ? placeholders of a condition after the
+// parameters of the query, or shifts its $n placeholders, which count the
+// parameters given to the builder, after the ones of subqueries and
+// expressions e.g. Where("name = $1", name) after a Select with a subquery
+func (q *QueryBuilder) bindPlaceholders(condition string, args int) string {
+	bound := bindPlaceholders(q.Dialect, condition, len(q.params), args)
+	if bound != condition || q.embedded == 0 {
+		return bound
+	}
+	return shiftPlaceholders(condition, q.embedded)
+}
+
+// hasTopLevelOr reports whether a condition has OR outside of parentheses
+// and quoted text
+func hasTopLevelOr(condition string) bool {
+	depth := 0
+	var quote byte
+	for i := 0; i < len(condition); i++ {
+		c := condition[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case depth == 0 && i > 0 && i+3 < len(condition) &&
+			condition[i-1] == ' ' && strings.EqualFold(condition[i:i+3], "OR "):
+			return true
+		}
+	}
+	return false
+}
+
+// quoteIdentifier quotes every part of a qualified identifier with the
+// quotes of the dialect e.g. public.users becomes "public"."users", parts
+// already quoted and * are left as is
+func quoteIdentifier(dialect Dialect, identifier string) string {
+	parts := strings.Split(identifier, ".")
+	for i, part := range parts {
+		if part == "*" || strings.HasPrefix(part, "\"") || strings.HasPrefix(part, "`") {
+			continue
+		}
+		parts[i] = dialect.Quote(part)
+	}
+	return strings.Join(parts, ".")
+}
@@ -0,0 +1,162 @@
+package goorm
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// WindowFunc is a window function call e.g.
+//
+//	qb.Select("id", goorm.Sum("amount").Over(goorm.PartitionBy("user_id"), goorm.OrderBy("created_at")).As("running_total")).
+//		From("payments")
+type WindowFunc struct {
+	name   string
+	args   []string
+	params []any
+	over   []WindowPart
+	window string
+}
+
+// WindowPart is a part of the window of a function or of a WINDOW clause
+type WindowPart interface {
+	windowSQL() string
+}
+
+type partitionBy []string
+
+func (p partitionBy) windowSQL() string { return "PARTITION BY " + strings.Join(p, ", ") }
+
+type orderBy []string
+
+func (o orderBy) windowSQL() string { return "ORDER BY " + strings.Join(o, ", ") }
+
+type frame string
+
+func (f frame) windowSQL() string { return string(f) }
+
+// windowName is a named window the window of a function is based on
+type windowName string
+
+func (w windowName) windowSQL() string { return string(w) }
+
+// PartitionBy splits the rows of a window by the columns
+func PartitionBy(columns ...string) WindowPart { return partitionBy(columns) }
+
+// OrderBy orders the rows of a window e.g. OrderBy("created_at DESC")
+func OrderBy(columns ...string) WindowPart { return orderBy(columns) }
+
+// Frame limits the rows of a window
+// e.g. Frame("ROWS BETWEEN 2 PRECEDING AND CURRENT ROW")
+func Frame(spec string) WindowPart { return frame(spec) }
+
+// Window bases a window on the named window of a WINDOW clause
+// e.g. Over(goorm.Window("w"), goorm.Frame("ROWS UNBOUNDED PRECEDING"))
+func Window(name string) WindowPart { return windowName(name) }
+
+// RowNumber numbers the rows of the window from 1
+func RowNumber() *WindowFunc { return &WindowFunc{name: "ROW_NUMBER"} }
+
+// Rank ranks the rows of the window with gaps for ties
+func Rank() *WindowFunc { return &WindowFunc{name: "RANK"} }
+
+// DenseRank ranks the rows of the window without gaps for ties
+func DenseRank() *WindowFunc { return &WindowFunc{name: "DENSE_RANK"} }
+
+// Lag returns the column offset rows before the current one, or def
+// when there is no such row e.g. Lag("amount", 1, 0)
+func Lag(column string, offset int, def ...any) *WindowFunc {
+	return offsetFunc("LAG", column, offset, def)
+}
+
+// Lead returns the column offset rows after the current one like Lag
+func Lead(column string, offset int, def ...any) *WindowFunc {
+	return offsetFunc("LEAD", column, offset, def)
+}
+
+func offsetFunc(name string, column string, offset int, def []any) *WindowFunc {
+	w := &WindowFunc{name: name, args: []string{column, strconv.Itoa(offset)}}
+	if len(def) > 0 {
+		w.params = append(w.params, def[0])
+	}
+	return w
+}
+
+// Sum adds up the column over the window
+func Sum(column string) *WindowFunc { return &WindowFunc{name: "SUM", args: []string{column}} }
+
+// Avg averages the column over the window
+func Avg(column string) *WindowFunc { return &WindowFunc{name: "AVG", args: []string{column}} }
+
+// Count counts the rows of the window with a value for the column, or all
+// of them with "*"
+func Count(column string) *WindowFunc { return &WindowFunc{name: "COUNT", args: []string{column}} }
+
+// Min returns the smallest value of the column in the window
+func Min(column string) *WindowFunc { return &WindowFunc{name: "MIN", args: []string{column}} }
+
+// Max returns the largest value of the column in the window
+func Max(column string) *WindowFunc { return &WindowFunc{name: "MAX", args: []string{column}} }
+
+// Over sets the window of the function, an empty one spans all rows
+func (w *WindowFunc) Over(parts ...WindowPart) *WindowFunc {
+	w.over = parts
+	w.window = ""
+	return w
+}
+
+// OverWindow uses the named window of a WINDOW clause as is
+func (w *WindowFunc) OverWindow(name string) *WindowFunc {
+	w.window = name
+	w.over = nil
+	return w
+}
+
+// As names the result of the function in a SELECT
+func (w *WindowFunc) As(name string) Expression {
+	return As(w, name)
+}
+
+func (w *WindowFunc) ToSQL(dialect Dialect) (string, []any) {
+	args := append([]string{}, w.args...)
+	if len(w.params) > 0 {
+		args = append(args, dialect.GetPlaceholder(1))
+	}
+
+	query := fmt.Sprintf("%s(%s) OVER ", w.name, strings.Join(args, ", "))
+	if w.window != "" {
+		return query + w.window, w.params
+	}
+	return query + "(" + windowSQL(w.over) + ")", w.params
+}
+
+func windowSQL(parts []WindowPart) string {
+	sql := make([]string, len(parts))
+	for i, part := range parts {
+		sql[i] = part.windowSQL()
+	}
+	return strings.Join(sql, " ")
+}
+
+// Window adds a WINDOW clause defining a named window the window functions
+// of the SELECT can use with OverWindow, it goes before ORDER BY
+// e.g.
+//
+//	qb.Select("id", goorm.Rank().OverWindow("w").As("rank")).
+//		From("scores").
+//		Window("w", goorm.PartitionBy("game_id"), goorm.OrderBy("score DESC"))
+func (q *QueryBuilder) Window(name string, parts ...WindowPart) *QueryBuilder {
+	if !supportsWindow(q.Dialect) {
+		q.err = fmt.Errorf("%s does not support window functions", dialectName(q.Dialect))
+		return q
+	}
+
+	if hasOperation(q.operations, "WINDOW") {
+		q.query.WriteString(", ")
+	} else {
+		q.operations = append(q.operations, "WINDOW")
+		q.query.WriteString(" WINDOW ")
+	}
+	q.query.WriteString(name + " AS (" + windowSQL(parts) + ")")
+	return q
+}